@@ -0,0 +1,119 @@
+package kafka
+
+import "strings"
+
+// The reasons behind the decisions of authorizeACLRequest, named after the
+// ones logged by Kafka's AclAuthorizer
+const (
+	aclDecisionSuperUser     = "super_user"
+	aclDecisionDenied        = "denied"
+	aclDecisionAllowed       = "allowed"
+	aclDecisionNoACLsFound   = "no_acls_found"
+	aclDecisionNoMatchingACL = "no_matching_allow_acl"
+)
+
+// impliedOperations are the operations whose Allow ACLs also allow another
+// operation, e.g. a principal allowed to Read a topic may Describe it
+var impliedOperations = map[string][]string{
+	"Describe":        {"Read", "Write", "Delete", "Alter"},
+	"DescribeConfigs": {"AlterConfigs"},
+}
+
+const wildcardPrincipal = "User:*"
+
+// aclRequest is an operation a principal requests on a resource
+type aclRequest struct {
+	Principal    string
+	Host         string
+	Operation    string
+	ResourceType string
+	ResourceName string
+}
+
+// aclDecision is whether a request is allowed, and the ACLs that decided it
+type aclDecision struct {
+	Allowed bool
+	Reason  string
+	ACLs    map[string]StringlyTypedACL
+}
+
+// authorizeACLRequest decides a request the way Kafka's AclAuthorizer does:
+// super users are allowed everything, a matching Deny ACL wins over any Allow
+// ACL, and when no ACL matches the resource the request is only allowed if
+// allowIfNoACLs is set, like allow.everyone.if.no.acl.found.
+func authorizeACLRequest(req aclRequest, bindings map[string]StringlyTypedACL, superUsers []string, allowIfNoACLs bool) aclDecision {
+	for _, u := range superUsers {
+		if u == req.Principal {
+			return aclDecision{Allowed: true, Reason: aclDecisionSuperUser}
+		}
+	}
+
+	resourceACLs := map[string]StringlyTypedACL{}
+	for id, a := range bindings {
+		if aclMatchesResource(a.Resource, req.ResourceType, req.ResourceName) {
+			resourceACLs[id] = a
+		}
+	}
+	if len(resourceACLs) == 0 {
+		return aclDecision{Allowed: allowIfNoACLs, Reason: aclDecisionNoACLsFound}
+	}
+
+	denied := matchingACLs(resourceACLs, req, "Deny", []string{req.Operation})
+	if len(denied) > 0 {
+		return aclDecision{Allowed: false, Reason: aclDecisionDenied, ACLs: denied}
+	}
+
+	allowed := matchingACLs(resourceACLs, req, "Allow", append([]string{req.Operation}, impliedOperations[req.Operation]...))
+	if len(allowed) > 0 {
+		return aclDecision{Allowed: true, Reason: aclDecisionAllowed, ACLs: allowed}
+	}
+
+	return aclDecision{Allowed: false, Reason: aclDecisionNoMatchingACL}
+}
+
+// aclMatchesResource returns whether the ACLs of r apply to the resource: a
+// literal name matches itself, * matches every name, and a prefixed name
+// matches the names starting with it
+func aclMatchesResource(r Resource, resourceType, name string) bool {
+	if r.Type != resourceType {
+		return false
+	}
+
+	switch r.PatternTypeFilter {
+	case "Literal":
+		return r.Name == name || r.Name == "*"
+	case "Prefixed":
+		return strings.HasPrefix(name, r.Name)
+	default:
+		return false
+	}
+}
+
+func matchingACLs(acls map[string]StringlyTypedACL, req aclRequest, permissionType string, operations []string) map[string]StringlyTypedACL {
+	matching := map[string]StringlyTypedACL{}
+	for id, a := range acls {
+		if a.ACL.PermissionType != permissionType {
+			continue
+		}
+		if a.ACL.Principal != req.Principal && a.ACL.Principal != wildcardPrincipal {
+			continue
+		}
+		if a.ACL.Host != req.Host && a.ACL.Host != "*" {
+			continue
+		}
+		if a.ACL.Operation != "All" && !stringInSlice(a.ACL.Operation, operations) {
+			continue
+		}
+		matching[id] = a
+	}
+	return matching
+}
+
+func stringInSlice(s string, slice []string) bool {
+	for _, v := range slice {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,128 @@
+package kafka
+
+import (
+	"reflect"
+	"testing"
+)
+
+func testBindings(ids ...string) map[string]StringlyTypedACL {
+	bindings := map[string]StringlyTypedACL{}
+	for _, id := range ids {
+		a, err := parseACLID(id)
+		if err != nil {
+			panic(err)
+		}
+		bindings[id] = a
+	}
+	return bindings
+}
+
+func Test_AuthorizeACLRequest(t *testing.T) {
+	read := aclRequest{
+		Principal:    "User:alice",
+		Host:         "10.0.0.5",
+		Operation:    "Read",
+		ResourceType: "Topic",
+		ResourceName: "orders.v1",
+	}
+	describe := read
+	describe.Operation = "Describe"
+
+	for name, tc := range map[string]struct {
+		req        aclRequest
+		bindings   []string
+		superUsers []string
+		allowIfNo  bool
+		allowed    bool
+		reason     string
+		deciding   []string
+	}{
+		"literal": {
+			req:      read,
+			bindings: []string{"User:alice|*|Read|Allow|Topic|orders.v1|Literal"},
+			allowed:  true,
+			reason:   aclDecisionAllowed,
+			deciding: []string{"User:alice|*|Read|Allow|Topic|orders.v1|Literal"},
+		},
+		"prefixed": {
+			req:      read,
+			bindings: []string{"User:alice|10.0.0.5|Read|Allow|Topic|orders.|Prefixed"},
+			allowed:  true,
+			reason:   aclDecisionAllowed,
+			deciding: []string{"User:alice|10.0.0.5|Read|Allow|Topic|orders.|Prefixed"},
+		},
+		"wildcard resource and principal": {
+			req:      read,
+			bindings: []string{"User:*|*|All|Allow|Topic|*|Literal"},
+			allowed:  true,
+			reason:   aclDecisionAllowed,
+			deciding: []string{"User:*|*|All|Allow|Topic|*|Literal"},
+		},
+		"deny wins": {
+			req: read,
+			bindings: []string{
+				"User:alice|*|Read|Allow|Topic|orders.v1|Literal",
+				"User:*|10.0.0.5|Read|Deny|Topic|orders.|Prefixed",
+			},
+			allowed:  false,
+			reason:   aclDecisionDenied,
+			deciding: []string{"User:*|10.0.0.5|Read|Deny|Topic|orders.|Prefixed"},
+		},
+		"describe implied by read and write": {
+			req: describe,
+			bindings: []string{
+				"User:alice|*|Read|Allow|Topic|orders.v1|Literal",
+				"User:alice|*|Write|Allow|Topic|orders.v1|Literal",
+				"User:alice|*|Create|Allow|Topic|orders.v1|Literal",
+			},
+			allowed: true,
+			reason:  aclDecisionAllowed,
+			deciding: []string{
+				"User:alice|*|Read|Allow|Topic|orders.v1|Literal",
+				"User:alice|*|Write|Allow|Topic|orders.v1|Literal",
+			},
+		},
+		"read not implied by describe": {
+			req: read,
+			bindings: []string{
+				"User:alice|*|Describe|Allow|Topic|orders.v1|Literal",
+			},
+			allowed: false,
+			reason:  aclDecisionNoMatchingACL,
+		},
+		"other host": {
+			req:      read,
+			bindings: []string{"User:alice|10.0.0.6|Read|Allow|Topic|orders.v1|Literal"},
+			allowed:  false,
+			reason:   aclDecisionNoMatchingACL,
+		},
+		"other resource type": {
+			req:       read,
+			bindings:  []string{"User:alice|*|Read|Allow|Group|orders.v1|Literal"},
+			allowIfNo: true,
+			allowed:   true,
+			reason:    aclDecisionNoACLsFound,
+		},
+		"no acls found": {
+			req:      read,
+			bindings: []string{"User:alice|*|Read|Allow|Topic|payments|Literal"},
+			allowed:  false,
+			reason:   aclDecisionNoACLsFound,
+		},
+		"super user": {
+			req:        read,
+			bindings:   []string{"User:alice|*|Read|Deny|Topic|orders.v1|Literal"},
+			superUsers: []string{"User:admin", "User:alice"},
+			allowed:    true,
+			reason:     aclDecisionSuperUser,
+		},
+	} {
+		decision := authorizeACLRequest(tc.req, testBindings(tc.bindings...), tc.superUsers, tc.allowIfNo)
+		if decision.Allowed != tc.allowed || decision.Reason != tc.reason {
+			t.Errorf("%s: got allowed=%v (%s), expected allowed=%v (%s)", name, decision.Allowed, decision.Reason, tc.allowed, tc.reason)
+		}
+		if got := aclIDs(decision.ACLs); (len(got) != 0 || len(tc.deciding) != 0) && !reflect.DeepEqual(got, tc.deciding) {
+			t.Errorf("%s: got deciding ACLs %v, expected %v", name, got, tc.deciding)
+		}
+	}
+}
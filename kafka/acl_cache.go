@@ -0,0 +1,49 @@
+package kafka
+
+import (
+	"sync"
+
+	"github.com/IBM/sarama"
+)
+
+// aclCache holds a snapshot of every ACL in the cluster, so that linting many
+// ACL resources against it costs a single listing instead of one per resource.
+// Concurrent readers share the same fetch, and any change made to the ACLs
+// through the client discards the snapshot.
+type aclCache struct {
+	mu    sync.Mutex
+	acls  []*sarama.ResourceAcls
+	fresh bool
+}
+
+func (a *aclCache) get(fetch func() ([]*sarama.ResourceAcls, error)) ([]*sarama.ResourceAcls, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.fresh {
+		return a.acls, nil
+	}
+
+	acls, err := fetch()
+	if err != nil {
+		return nil, err
+	}
+
+	a.acls = acls
+	a.fresh = true
+	return acls, nil
+}
+
+func (a *aclCache) invalidate() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.acls = nil
+	a.fresh = false
+}
+
+// CachedACLs returns every ACL in the cluster, listing them only when they
+// changed since the last listing
+func (c *Client) CachedACLs() ([]*sarama.ResourceAcls, error) {
+	return c.aclCache.get(c.ListACLs)
+}
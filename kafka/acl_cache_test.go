@@ -0,0 +1,65 @@
+package kafka
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/IBM/sarama"
+)
+
+func Test_ACLCacheFetchesOnce(t *testing.T) {
+	var cache aclCache
+	var mu sync.Mutex
+	fetches := 0
+	fetch := func() ([]*sarama.ResourceAcls, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		fetches++
+		return []*sarama.ResourceAcls{}, nil
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := cache.get(fetch); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if fetches != 1 {
+		t.Errorf("Expected a single fetch, got %d", fetches)
+	}
+
+	cache.invalidate()
+	if _, err := cache.get(fetch); err != nil {
+		t.Fatal(err)
+	}
+	if fetches != 2 {
+		t.Errorf("Expected the ACLs to be fetched again once invalidated, got %d fetches", fetches)
+	}
+}
+
+func Test_ACLCacheDoesNotKeepErrors(t *testing.T) {
+	var cache aclCache
+	_, err := cache.get(func() ([]*sarama.ResourceAcls, error) {
+		return nil, errors.New("broker unavailable")
+	})
+	if err == nil {
+		t.Fatal("Expected an error")
+	}
+
+	acls, err := cache.get(func() ([]*sarama.ResourceAcls, error) {
+		return []*sarama.ResourceAcls{{}}, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(acls) != 1 {
+		t.Errorf("Expected the ACLs to be fetched again after an error, got %v", acls)
+	}
+}
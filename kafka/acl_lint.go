@@ -0,0 +1,105 @@
+package kafka
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// aclLintWarnings holds the lint warnings of the planned ACLs, keyed by ID.
+// Applying an ACL plans it again first, so creating it reports the warnings
+// of that plan instead of linting it once more.
+type aclLintWarnings struct {
+	mu       sync.Mutex
+	warnings map[string][]string
+}
+
+func (l *aclLintWarnings) set(id string, warnings []string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.warnings == nil {
+		l.warnings = map[string][]string{}
+	}
+	l.warnings[id] = warnings
+}
+
+// take returns the warnings of the ACL, forgetting them so that they're only
+// reported once
+func (l *aclLintWarnings) take(id string) []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	warnings := l.warnings[id]
+	delete(l.warnings, id)
+	return warnings
+}
+
+// lintACL returns the problems of the ACL a, given the other ACLs of the
+// cluster: granting or denying every operation to every principal, an Allow
+// ACL that a Deny ACL fully shadows, and an ACL that another one already
+// covers.
+func lintACL(a StringlyTypedACL, existing map[string]StringlyTypedACL) []string {
+	warnings := []string{}
+
+	if a.ACL.Principal == wildcardPrincipal && a.ACL.Operation == "All" {
+		warnings = append(warnings, fmt.Sprintf("%s %ss every operation to every principal", a, strings.ToLower(a.ACL.PermissionType)))
+	}
+
+	for _, id := range aclIDs(existing) {
+		b := existing[id]
+		if id == a.String() {
+			continue
+		}
+
+		switch {
+		case a.ACL.PermissionType == "Allow" && b.ACL.PermissionType == "Deny" && aclCovers(b, a, []string{a.ACL.Operation}):
+			warnings = append(warnings, fmt.Sprintf("%s is shadowed by %s, which denies everything it allows", a, b))
+		case a.ACL.PermissionType == b.ACL.PermissionType && aclCovers(b, a, coveringOperations(a)):
+			warnings = append(warnings, fmt.Sprintf("%s is redundant, %s already covers it", a, b))
+		}
+	}
+
+	sort.Strings(warnings)
+	return warnings
+}
+
+// coveringOperations returns the operations whose ACLs cover the operation of
+// a, besides All
+func coveringOperations(a StringlyTypedACL) []string {
+	operations := []string{a.ACL.Operation}
+	if a.ACL.PermissionType == "Allow" {
+		operations = append(operations, impliedOperations[a.ACL.Operation]...)
+	}
+	return operations
+}
+
+// aclCovers returns whether the ACL b applies to every request the ACL a
+// applies to, when b is for one of operations
+func aclCovers(b, a StringlyTypedACL, operations []string) bool {
+	if b.ACL.Principal != a.ACL.Principal && b.ACL.Principal != wildcardPrincipal {
+		return false
+	}
+	if b.ACL.Host != a.ACL.Host && b.ACL.Host != "*" {
+		return false
+	}
+	if b.ACL.Operation != "All" && !stringInSlice(b.ACL.Operation, operations) {
+		return false
+	}
+	if b.Resource.Type != a.Resource.Type {
+		return false
+	}
+
+	switch {
+	case b.Resource.PatternTypeFilter == "Literal" && b.Resource.Name == "*":
+		return true
+	case b.Resource.PatternTypeFilter == "Literal":
+		return a.Resource.PatternTypeFilter == "Literal" && a.Resource.Name == b.Resource.Name
+	case b.Resource.PatternTypeFilter == "Prefixed":
+		return (a.Resource.PatternTypeFilter == "Literal" || a.Resource.PatternTypeFilter == "Prefixed") &&
+			a.Resource.Name != "*" && strings.HasPrefix(a.Resource.Name, b.Resource.Name)
+	default:
+		return false
+	}
+}
@@ -0,0 +1,84 @@
+package kafka
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_LintACL(t *testing.T) {
+	for name, tc := range map[string]struct {
+		acl      string
+		existing []string
+		expected []string
+	}{
+		"no problems": {
+			acl: "User:alice|*|Read|Allow|Topic|orders|Literal",
+			existing: []string{
+				"User:alice|*|Read|Allow|Topic|orders|Literal",
+				"User:alice|*|Write|Allow|Topic|orders|Literal",
+				"User:bob|*|Read|Deny|Topic|orders|Literal",
+				"User:alice|*|Read|Deny|Topic|payments|Literal",
+			},
+			expected: []string{},
+		},
+		"wildcard principal with All": {
+			acl:      "User:*|*|All|Allow|Topic|orders|Literal",
+			expected: []string{"User:*|*|All|Allow|Topic|orders|Literal allows every operation to every principal"},
+		},
+		"shadowed by a prefixed deny": {
+			acl:      "User:alice|10.0.0.5|Read|Allow|Topic|orders.v1|Literal",
+			existing: []string{"User:*|*|All|Deny|Topic|orders.|Prefixed"},
+			expected: []string{"User:alice|10.0.0.5|Read|Allow|Topic|orders.v1|Literal is shadowed by User:*|*|All|Deny|Topic|orders.|Prefixed, which denies everything it allows"},
+		},
+		"partially shadowed": {
+			acl:      "User:alice|*|Read|Allow|Topic|orders|Literal",
+			existing: []string{"User:alice|10.0.0.5|Read|Deny|Topic|orders|Literal"},
+			expected: []string{},
+		},
+		"redundant with a wildcard resource": {
+			acl:      "User:alice|*|Read|Allow|Topic|orders|Literal",
+			existing: []string{"User:alice|*|Read|Allow|Topic|*|Literal"},
+			expected: []string{"User:alice|*|Read|Allow|Topic|orders|Literal is redundant, User:alice|*|Read|Allow|Topic|*|Literal already covers it"},
+		},
+		"redundant with a shorter prefix": {
+			acl:      "User:alice|*|Write|Deny|Topic|orders.v1|Prefixed",
+			existing: []string{"User:alice|*|Write|Deny|Topic|orders.|Prefixed"},
+			expected: []string{"User:alice|*|Write|Deny|Topic|orders.v1|Prefixed is redundant, User:alice|*|Write|Deny|Topic|orders.|Prefixed already covers it"},
+		},
+		"describe implied by read": {
+			acl:      "User:alice|*|Describe|Allow|Topic|orders|Literal",
+			existing: []string{"User:alice|*|Read|Allow|Topic|orders|Literal"},
+			expected: []string{"User:alice|*|Describe|Allow|Topic|orders|Literal is redundant, User:alice|*|Read|Allow|Topic|orders|Literal already covers it"},
+		},
+		"literal doesn't cover prefixed": {
+			acl:      "User:alice|*|Read|Allow|Topic|orders|Prefixed",
+			existing: []string{"User:alice|*|Read|Allow|Topic|orders|Literal"},
+			expected: []string{},
+		},
+	} {
+		a, err := parseACLID(tc.acl)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		got := lintACL(a, testBindings(tc.existing...))
+		if !reflect.DeepEqual(got, tc.expected) {
+			t.Errorf("%s: got %v, expected %v", name, got, tc.expected)
+		}
+	}
+}
+
+func Test_ACLLintWarningsAreTakenOnce(t *testing.T) {
+	var l aclLintWarnings
+	if got := l.take("missing"); len(got) != 0 {
+		t.Errorf("Expected no warnings for an ACL that wasn't planned, got %v", got)
+	}
+
+	l.set("a", []string{"redundant"})
+	if got := l.take("a"); !reflect.DeepEqual(got, []string{"redundant"}) {
+		t.Errorf("Got %v, expected the planned warnings", got)
+	}
+	if got := l.take("a"); len(got) != 0 {
+		t.Errorf("Expected the warnings to be reported once, got %v", got)
+	}
+}
@@ -0,0 +1,161 @@
+package kafka
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/IBM/sarama"
+)
+
+// CreateACLs creates all the ACLs with one request. It returns the errors of
+// individual ACLs keyed by their ID, separately from errors affecting the
+// whole request.
+func (c *Client) CreateACLs(acls []StringlyTypedACL) (map[string]error, error) {
+	defer c.aclCache.invalidate()
+	failed := map[string]error{}
+
+	valid := []StringlyTypedACL{}
+	creations := []*sarama.AclCreation{}
+	for _, a := range acls {
+		ac, err := tfToAclCreation(a)
+		if err == nil {
+			err = c.checkACLSupported(a)
+		}
+		if err != nil {
+			failed[a.String()] = err
+			continue
+		}
+		valid = append(valid, a)
+		creations = append(creations, ac)
+	}
+
+	if len(creations) == 0 {
+		return failed, nil
+	}
+
+	broker, err := c.client.Controller()
+	if err != nil {
+		return failed, err
+	}
+
+	req := &sarama.CreateAclsRequest{
+		Version:      c.getCreateAclsRequestAPIVersion(),
+		AclCreations: creations,
+	}
+	log.Printf("[INFO] Creating %d ACLs", len(creations))
+	res, err := broker.CreateAcls(req)
+	if err != nil {
+		return failed, err
+	}
+
+	// the responses are in the order of the creations
+	if len(res.AclCreationResponses) != len(valid) {
+		return failed, fmt.Errorf("expected %d ACL creation responses, got %d", len(valid), len(res.AclCreationResponses))
+	}
+	for i, r := range res.AclCreationResponses {
+		if r.Err != sarama.ErrNoError {
+			failed[valid[i].String()] = aclError(r.Err, r.ErrMsg)
+		}
+	}
+
+	return failed, nil
+}
+
+// DeleteACLs deletes all the ACLs with one request. ACLs that don't exist
+// aren't reported as errors. ACLs with Any or Match fields are described
+// first, like DeleteACL does, and refused when their filters match other
+// bindings, unless allow_broad_acl_deletion is set. Every deleted binding is
+// logged.
+func (c *Client) DeleteACLs(acls []StringlyTypedACL) (map[string]error, error) {
+	defer c.aclCache.invalidate()
+	failed := map[string]error{}
+
+	valid := []StringlyTypedACL{}
+	filters := []*sarama.AclFilter{}
+	for _, a := range acls {
+		f, err := tfToAclFilter(a)
+		if err == nil {
+			err = c.checkACLDeletion(a)
+		}
+		if err != nil {
+			failed[a.String()] = err
+			continue
+		}
+		valid = append(valid, a)
+		filters = append(filters, &f)
+	}
+
+	if len(filters) == 0 {
+		return failed, nil
+	}
+
+	broker, err := c.client.Controller()
+	if err != nil {
+		return failed, err
+	}
+
+	req := &sarama.DeleteAclsRequest{
+		Version: int(c.getDeleteAclsRequestAPIVersion()),
+		Filters: filters,
+	}
+	log.Printf("[INFO] Deleting %d ACLs", len(filters))
+	res, err := broker.DeleteAcls(req)
+	if err != nil {
+		return failed, err
+	}
+
+	// the responses are in the order of the filters
+	if len(res.FilterResponses) != len(valid) {
+		return failed, fmt.Errorf("expected %d ACL deletion responses, got %d", len(valid), len(res.FilterResponses))
+	}
+	for i, r := range res.FilterResponses {
+		if r.Err != sarama.ErrNoError {
+			failed[valid[i].String()] = aclError(r.Err, r.ErrMsg)
+			continue
+		}
+		for _, m := range r.MatchingAcls {
+			if m.Err != sarama.ErrNoError {
+				failed[valid[i].String()] = aclError(m.Err, m.ErrMsg)
+				continue
+			}
+			if d := matchingACLBinding(m); d.String() != valid[i].String() {
+				log.Printf("[WARN] Deleted ACL %s, matched by %s", d, valid[i])
+			} else {
+				log.Printf("[INFO] Deleted ACL %s", d)
+			}
+		}
+	}
+
+	return failed, nil
+}
+
+// aclBindings returns every ACL of the described resources, keyed by ID
+func aclBindings(resources []*sarama.ResourceAcls) map[string]StringlyTypedACL {
+	bindings := map[string]StringlyTypedACL{}
+	for _, r := range resources {
+		for _, acl := range r.Acls {
+			a := StringlyTypedACL{
+				ACL: ACL{
+					Principal:      acl.Principal,
+					Host:           acl.Host,
+					Operation:      ACLOperationToString(acl.Operation),
+					PermissionType: ACLPermissionTypeToString(acl.PermissionType),
+				},
+				Resource: Resource{
+					Type:              ACLResourceToString(r.ResourceType),
+					Name:              r.ResourceName,
+					PatternTypeFilter: resourcePatternToString(r.ResourcePatternType),
+				},
+			}
+			bindings[a.String()] = a
+		}
+	}
+	return bindings
+}
+
+func aclError(kerr sarama.KError, msg *string) error {
+	if msg != nil && *msg != "" {
+		return fmt.Errorf("%s - %s", kerr, *msg)
+	}
+	return kerr
+}
@@ -0,0 +1,354 @@
+package kafka
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/IBM/sarama"
+)
+
+// The methods below operate on many topics with a single request each, so that
+// managing thousands of topics doesn't take thousands of round trips. They
+// return the errors of individual topics, keyed by topic name, separately from
+// errors affecting the whole request.
+
+// https://kafka.apache.org/protocol#The_Messages_CreateTopics
+const apiKeyCreateTopics = 19
+
+// CanValidateTopics is true when the brokers support creating topics in
+// validate-only mode
+func (c *Client) CanValidateTopics() bool {
+	return c.versionForKey(apiKeyCreateTopics, 1) >= 1
+}
+
+// CreateTopics creates all the topics with one request. With validateOnly, the
+// brokers only check that the topics could be created.
+func (c *Client) CreateTopics(topics []Topic, validateOnly bool, timeout time.Duration) (map[string]error, error) {
+	failed := map[string]error{}
+	if len(topics) == 0 {
+		return failed, nil
+	}
+
+	if validateOnly && !c.CanValidateTopics() {
+		return failed, errors.New("Need kafka >= 0.10.2.0 to validate topics")
+	}
+
+	broker, err := c.client.Controller()
+	if err != nil {
+		return failed, err
+	}
+
+	details := make(map[string]*sarama.TopicDetail, len(topics))
+	for _, t := range topics {
+		details[t.Name] = &sarama.TopicDetail{
+			NumPartitions:     t.Partitions,
+			ReplicationFactor: t.ReplicationFactor,
+			ConfigEntries:     t.Config,
+		}
+	}
+
+	req := &sarama.CreateTopicsRequest{
+		Version:      int16(c.versionForKey(apiKeyCreateTopics, 1)),
+		TopicDetails: details,
+		Timeout:      timeout,
+		ValidateOnly: validateOnly,
+	}
+	log.Printf("[INFO] Creating %d topics in Kafka (validate only: %v)", len(topics), validateOnly)
+	res, err := broker.CreateTopics(req)
+	if err != nil {
+		return failed, err
+	}
+
+	for name, e := range res.TopicErrors {
+		if e.Err != sarama.ErrNoError {
+			failed[name] = e
+		}
+	}
+
+	return failed, nil
+}
+
+// DeleteTopics deletes all the topics with one request
+func (c *Client) DeleteTopics(names []string, timeout time.Duration) (map[string]error, error) {
+	failed := map[string]error{}
+	if len(names) == 0 {
+		return failed, nil
+	}
+
+	broker, err := c.client.Controller()
+	if err != nil {
+		return failed, err
+	}
+
+	req := &sarama.DeleteTopicsRequest{
+		Topics:  names,
+		Timeout: timeout,
+	}
+	log.Printf("[INFO] Deleting %d topics from Kafka", len(names))
+	res, err := broker.DeleteTopics(req)
+	if err != nil {
+		return failed, err
+	}
+
+	for name, e := range res.TopicErrorCodes {
+		if e != sarama.ErrNoError && e != sarama.ErrUnknownTopicOrPartition {
+			failed[name] = e
+		}
+	}
+
+	return failed, nil
+}
+
+// UpdateTopics sets and removes the configs that changed between the old
+// topics and the given ones with one request, leaving the other configs of the
+// topics, like the ones changed outside of Terraform, alone
+func (c *Client) UpdateTopics(old map[string]Topic, topics []Topic) (map[string]error, error) {
+	failed := map[string]error{}
+	if len(topics) == 0 {
+		return failed, nil
+	}
+
+	broker, err := c.client.Controller()
+	if err != nil {
+		return failed, err
+	}
+
+	changes := make(map[string]topicConfigChange, len(topics))
+	for _, t := range topics {
+		set, remove := configChanges(configInterfaces(old[t.Name].Config), configInterfaces(t.Config))
+		changes[t.Name] = topicConfigChange{Set: set, Remove: remove}
+	}
+
+	log.Printf("[INFO] Updating the config of %d topics in Kafka", len(topics))
+	var results []*sarama.AlterConfigsResourceResponse
+	if c.CanIncrementallyAlterConfigs() {
+		res, err := broker.IncrementalAlterConfigs(&sarama.IncrementalAlterConfigsRequest{
+			Resources: incrementalConfigResources(changes),
+		})
+		if err != nil {
+			return failed, err
+		}
+		results = res.Resources
+	} else {
+		// AlterConfigs replaces every config of the topics, so the
+		// configs that don't change have to be sent as they are
+		current, err := c.ReadTopics(sortedConfigChanges(changes))
+		if err != nil {
+			return failed, err
+		}
+
+		resources := []*sarama.AlterConfigsResource{}
+		for _, name := range sortedConfigChanges(changes) {
+			resources = append(resources, configToResources(Topic{
+				Name:   name,
+				Config: configAfterChanges(current[name].EffectiveConfig, changes[name].Set, changes[name].Remove),
+			})...)
+		}
+		res, err := broker.AlterConfigs(&sarama.AlterConfigsRequest{Resources: resources})
+		if err != nil {
+			return failed, err
+		}
+		results = res.Resources
+	}
+
+	for _, e := range results {
+		if e.ErrorCode != int16(sarama.ErrNoError) {
+			failed[e.Name] = resourceError(e.ErrorCode, e.ErrorMsg)
+		}
+	}
+
+	return failed, nil
+}
+
+// AddPartitionsToTopics increases the partitions of all the topics to their
+// Partitions with one request
+func (c *Client) AddPartitionsToTopics(topics []Topic, timeout time.Duration) (map[string]error, error) {
+	failed := map[string]error{}
+	if len(topics) == 0 {
+		return failed, nil
+	}
+
+	broker, err := c.client.Controller()
+	if err != nil {
+		return failed, err
+	}
+
+	tp := make(map[string]*sarama.TopicPartition, len(topics))
+	for _, t := range topics {
+		tp[t.Name] = &sarama.TopicPartition{Count: t.Partitions}
+	}
+
+	req := &sarama.CreatePartitionsRequest{
+		TopicPartitions: tp,
+		Timeout:         timeout,
+	}
+	log.Printf("[INFO] Adding partitions to %d topics in Kafka", len(topics))
+	res, err := broker.CreatePartitions(req)
+	if err != nil {
+		return failed, err
+	}
+
+	for name, e := range res.TopicPartitionErrors {
+		if e.Err != sarama.ErrNoError {
+			failed[name] = e
+		}
+	}
+
+	return failed, nil
+}
+
+// ReadTopics reads all the topics after a single metadata refresh, with one
+// DescribeConfigs request. Topics that don't exist are left out of the result.
+func (c *Client) ReadTopics(names []string) (map[string]Topic, error) {
+	topics := make(map[string]Topic, len(names))
+
+	if err := c.client.RefreshMetadata(); err != nil {
+		return topics, err
+	}
+	if err := c.extractTopics(); err != nil {
+		return topics, err
+	}
+
+	resources := []*sarama.ConfigResource{}
+	for _, name := range names {
+		if _, ok := c.topics[name]; !ok {
+			log.Printf("[DEBUG] Topic %s doesn't exist", name)
+			continue
+		}
+
+		partitions, err := c.client.Partitions(name)
+		if err != nil {
+			return topics, err
+		}
+
+		counts, err := ReplicaCounts(c.client, name, partitions)
+		if err != nil {
+			return topics, err
+		}
+
+		t := Topic{
+			Name:       name,
+			Partitions: int32(len(partitions)),
+		}
+		rf, uniform := majorityReplicationFactor(counts)
+		t.ReplicationFactor = rf
+		if !uniform {
+			t.PartitionReplicationFactors = counts
+		}
+
+		topics[name] = t
+		resources = append(resources, &sarama.ConfigResource{
+			Type: sarama.TopicResource,
+			Name: name,
+		})
+	}
+
+	if len(resources) == 0 {
+		return topics, nil
+	}
+
+	broker, err := c.client.Controller()
+	if err != nil {
+		return topics, err
+	}
+
+	cr, err := broker.DescribeConfigs(&sarama.DescribeConfigsRequest{
+		Version:   c.getDescribeConfigAPIVersion(),
+		Resources: resources,
+	})
+	if err != nil {
+		return topics, err
+	}
+
+	for _, res := range cr.Resources {
+		if res.ErrorCode == int16(sarama.ErrUnknownTopicOrPartition) {
+			// deleted since the metadata was refreshed
+			delete(topics, res.Name)
+			continue
+		}
+		if res.ErrorCode != int16(sarama.ErrNoError) {
+			return topics, fmt.Errorf("%s: %s", res.Name, resourceError(res.ErrorCode, res.ErrorMsg))
+		}
+
+		t := topics[res.Name]
+		t.Config, t.EffectiveConfig = topicConfigFromResponse(res, cr.Version)
+		topics[res.Name] = t
+	}
+
+	return topics, nil
+}
+
+// topicConfigChange is the configs to set and the ones to remove on a topic
+type topicConfigChange struct {
+	Set    map[string]*string
+	Remove []string
+}
+
+func sortedConfigChanges(changes map[string]topicConfigChange) []string {
+	names := make([]string, 0, len(changes))
+	for name := range changes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// incrementalConfigResources returns the resources of an
+// IncrementalAlterConfigs request making the changes
+func incrementalConfigResources(changes map[string]topicConfigChange) []*sarama.IncrementalAlterConfigsResource {
+	resources := make([]*sarama.IncrementalAlterConfigsResource, 0, len(changes))
+	for _, name := range sortedConfigChanges(changes) {
+		change := changes[name]
+		entries := make(map[string]sarama.IncrementalAlterConfigsEntry, len(change.Set)+len(change.Remove))
+		for k, v := range change.Set {
+			entries[k] = sarama.IncrementalAlterConfigsEntry{
+				Operation: sarama.IncrementalAlterConfigsOperationSet,
+				Value:     v,
+			}
+		}
+		for _, k := range change.Remove {
+			entries[k] = sarama.IncrementalAlterConfigsEntry{
+				Operation: sarama.IncrementalAlterConfigsOperationDelete,
+			}
+		}
+
+		resources = append(resources, &sarama.IncrementalAlterConfigsResource{
+			Type:          sarama.TopicResource,
+			Name:          name,
+			ConfigEntries: entries,
+		})
+	}
+	return resources
+}
+
+// configInterfaces converts a topic config to the representation used in the
+// schema
+func configInterfaces(config map[string]*string) map[string]interface{} {
+	converted := make(map[string]interface{}, len(config))
+	for k, v := range config {
+		if v != nil {
+			converted[k] = *v
+		}
+	}
+	return converted
+}
+
+func resourceError(code int16, msg string) error {
+	if msg != "" {
+		return fmt.Errorf("%s - %s", sarama.KError(code), msg)
+	}
+	return sarama.KError(code)
+}
+
+// sortedErrors returns the keys of the failed topics or ACLs in order
+func sortedErrors(failed map[string]error) []string {
+	names := make([]string, 0, len(failed))
+	for name := range failed {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
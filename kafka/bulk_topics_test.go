@@ -0,0 +1,35 @@
+package kafka
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/IBM/sarama"
+)
+
+func Test_IncrementalConfigResources(t *testing.T) {
+	day := "86400000"
+	old := Topic{Name: "orders", Config: map[string]*string{"retention.ms": &day, "segment.ms": &day}}
+	week := "604800000"
+	topic := Topic{Name: "orders", Config: map[string]*string{"retention.ms": &week}}
+
+	set, remove := configChanges(configInterfaces(old.Config), configInterfaces(topic.Config))
+	resources := incrementalConfigResources(map[string]topicConfigChange{
+		"orders": {Set: set, Remove: remove},
+	})
+
+	// configs changed outside of Terraform, like ignored ones, aren't sent
+	expected := []*sarama.IncrementalAlterConfigsResource{
+		{
+			Type: sarama.TopicResource,
+			Name: "orders",
+			ConfigEntries: map[string]sarama.IncrementalAlterConfigsEntry{
+				"retention.ms": {Operation: sarama.IncrementalAlterConfigsOperationSet, Value: &week},
+				"segment.ms":   {Operation: sarama.IncrementalAlterConfigsOperationDelete},
+			},
+		},
+	}
+	if !reflect.DeepEqual(resources, expected) {
+		t.Errorf("Got %+v, expected %+v", resources[0], expected[0])
+	}
+}
@@ -5,9 +5,11 @@ import (
 	"fmt"
 	"log"
 	"math/rand"
+	"sort"
+	"strconv"
 	"time"
 
-	"github.com/Shopify/sarama"
+	"github.com/IBM/sarama"
 )
 
 type TopicMissingError struct {
@@ -26,6 +28,8 @@ type Client struct {
 	config        *Config
 	supportedAPIs map[int]int
 	topics        map[string]void
+	aclCache      aclCache
+	topicIDConns  brokerConnCache
 }
 
 func NewClient(config *Config) (*Client, error) {
@@ -77,8 +81,26 @@ func (c *Client) SaramaClient() sarama.Client {
 	return c.client
 }
 
+// dialBroker opens a connection of its own to the broker at addr, with the
+// config changed by configure, for requests the connections of the sarama
+// client can't send
+func (c *Client) dialBroker(addr string, configure func(*sarama.Config)) (*sarama.Broker, error) {
+	config := *c.kafkaConfig
+	configure(&config)
+
+	broker := sarama.NewBroker(addr)
+	if err := broker.Open(&config); err != nil {
+		return nil, err
+	}
+	if _, err := broker.Connected(); err != nil {
+		return nil, err
+	}
+
+	return broker, nil
+}
+
 func (c *Client) populateAPIVersions() error {
-	ch := make(chan []*sarama.ApiVersionsResponseBlock)
+	ch := make(chan []sarama.ApiVersionsResponseKey)
 	errCh := make(chan error)
 
 	brokers := c.client.Brokers()
@@ -99,7 +121,7 @@ func (c *Client) populateAPIVersions() error {
 	}
 
 	if len(errs) != 0 {
-		return errors.New(sarama.MultiError{Errors: &errs}.PrettyError())
+		return errors.Join(errs...)
 	}
 
 	c.supportedAPIs = make(map[int]int, len(clusterApiVersions))
@@ -120,15 +142,15 @@ func (c *Client) populateAPIVersions() error {
 	return nil
 }
 
-func apiVersionsFromBroker(broker *sarama.Broker, config *sarama.Config, ch chan<- []*sarama.ApiVersionsResponseBlock, errCh chan<- error) {
+func apiVersionsFromBroker(broker *sarama.Broker, config *sarama.Config, ch chan<- []sarama.ApiVersionsResponseKey, errCh chan<- error) {
 	resp, err := rawApiVersionsRequest(broker, config)
 
 	if err != nil {
 		errCh <- err
-	} else if resp.Err != sarama.ErrNoError {
-		errCh <- errors.New(resp.Err.Error())
+	} else if kerr := sarama.KError(resp.ErrorCode); kerr != sarama.ErrNoError {
+		errCh <- kerr
 	} else {
-		ch <- resp.ApiVersions
+		ch <- resp.ApiKeys
 	}
 }
 
@@ -146,7 +168,7 @@ func rawApiVersionsRequest(broker *sarama.Broker, config *sarama.Config) (*saram
 	return broker.ApiVersions(&sarama.ApiVersionsRequest{})
 }
 
-func updateClusterApiVersions(clusterApiVersions *map[int][2]int, brokerApiVersions []*sarama.ApiVersionsResponseBlock) {
+func updateClusterApiVersions(clusterApiVersions *map[int][2]int, brokerApiVersions []sarama.ApiVersionsResponseKey) {
 	cluster := *clusterApiVersions
 
 	for _, apiBlock := range brokerApiVersions {
@@ -191,13 +213,22 @@ func (c *Client) extractTopics() error {
 	return nil
 }
 
-func (c *Client) DeleteTopic(t string) error {
+// Topics returns the names of the topics known from the last metadata
+// refresh.
+func (c *Client) Topics() []string {
+	topics := make([]string, 0, len(c.topics))
+	for t := range c.topics {
+		topics = append(topics, t)
+	}
+	return topics
+}
+
+func (c *Client) DeleteTopic(t string, timeout time.Duration) error {
 	broker, err := c.client.Controller()
 	if err != nil {
 		return err
 	}
 
-	timeout := time.Duration(c.config.Timeout) * time.Second
 	req := &sarama.DeleteTopicsRequest{
 		Topics:  []string{t},
 		Timeout: timeout,
@@ -206,7 +237,7 @@ func (c *Client) DeleteTopic(t string) error {
 	if err == nil {
 		for k, e := range res.TopicErrorCodes {
 			if e != sarama.ErrNoError {
-				return fmt.Errorf("%s : %s", k, e)
+				return fmt.Errorf("%s : %w", k, e)
 			}
 		}
 	} else {
@@ -219,41 +250,156 @@ func (c *Client) DeleteTopic(t string) error {
 	return nil
 }
 
-func (c *Client) UpdateTopic(topic Topic) error {
-	broker, err := c.client.Controller()
+// TopicDeletionEnabled reports whether the controller allows deleting topics.
+// Brokers before Kafka 2.1.0 accept DeleteTopics requests when
+// delete.topic.enable is false, but never delete the topics.
+func (c *Client) TopicDeletionEnabled() (bool, error) {
+	controller, err := c.client.Controller()
+	if err != nil {
+		return false, err
+	}
+
+	request := &sarama.DescribeConfigsRequest{
+		Version: c.getDescribeConfigAPIVersion(),
+		Resources: []*sarama.ConfigResource{
+			{
+				Type:        sarama.BrokerResource,
+				Name:        strconv.Itoa(int(controller.ID())),
+				ConfigNames: []string{"delete.topic.enable"},
+			},
+		},
+	}
+
+	res, err := controller.DescribeConfigs(request)
 	if err != nil {
+		return false, err
+	}
+
+	for _, r := range res.Resources {
+		if r.ErrorCode != int16(sarama.ErrNoError) {
+			return false, resourceError(r.ErrorCode, r.ErrorMsg)
+		}
+		for _, e := range r.Configs {
+			if e.Name == "delete.topic.enable" {
+				return e.Value != "false", nil
+			}
+		}
+	}
+
+	// not reported, so it has its default value
+	return true, nil
+}
+
+// PurgeTopic deletes every record of the topic, keeping the topic itself
+func (c *Client) PurgeTopic(topic string, timeout time.Duration) error {
+	if err := c.client.RefreshMetadata(topic); err != nil {
 		return err
 	}
 
-	r := &sarama.AlterConfigsRequest{
-		Resources:    configToResources(topic),
-		ValidateOnly: false,
+	partitions, err := c.client.Partitions(topic)
+	if err != nil {
+		return err
 	}
 
-	res, err := broker.AlterConfigs(r)
+	ends, err := c.endOffsets(topic, partitions)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("[INFO] Deleting the records of %d partitions of %s", len(partitions), topic)
+	_, err = c.DeleteRecords(topic, ends, timeout)
+	return err
+}
 
+func (c *Client) CanIncrementallyAlterConfigs() bool {
+	_, ok := c.supportedAPIs[44] // https://kafka.apache.org/protocol#The_Messages_IncrementalAlterConfigs
+	return ok
+}
+
+// AlterTopicConfig sets and removes the given configs of a topic, leaving its
+// other configs alone
+func (c *Client) AlterTopicConfig(topic string, set map[string]*string, remove []string) error {
+	if len(set) == 0 && len(remove) == 0 {
+		return nil
+	}
+
+	broker, err := c.client.Controller()
 	if err != nil {
 		return err
 	}
 
-	if err == nil {
+	if !c.CanIncrementallyAlterConfigs() {
+		// AlterConfigs replaces every config of the topic, so the
+		// configs that don't change have to be sent as they are
+		_, effective, err := c.topicConfig(topic)
+		if err != nil {
+			return err
+		}
+
+		r := &sarama.AlterConfigsRequest{
+			Resources: configToResources(Topic{
+				Name:   topic,
+				Config: configAfterChanges(effective, set, remove),
+			}),
+			ValidateOnly: false,
+		}
+
+		res, err := broker.AlterConfigs(r)
+		if err != nil {
+			return err
+		}
+
 		for _, e := range res.Resources {
 			if e.ErrorCode != int16(sarama.ErrNoError) {
 				return errors.New(e.ErrorMsg)
 			}
 		}
+		return nil
+	}
+
+	entries := make(map[string]sarama.IncrementalAlterConfigsEntry, len(set)+len(remove))
+	for k, v := range set {
+		entries[k] = sarama.IncrementalAlterConfigsEntry{
+			Operation: sarama.IncrementalAlterConfigsOperationSet,
+			Value:     v,
+		}
+	}
+	for _, k := range remove {
+		entries[k] = sarama.IncrementalAlterConfigsEntry{
+			Operation: sarama.IncrementalAlterConfigsOperationDelete,
+		}
+	}
+
+	r := &sarama.IncrementalAlterConfigsRequest{
+		Resources: []*sarama.IncrementalAlterConfigsResource{
+			{
+				Type:          sarama.TopicResource,
+				Name:          topic,
+				ConfigEntries: entries,
+			},
+		},
+	}
+
+	res, err := broker.IncrementalAlterConfigs(r)
+	if err != nil {
+		return err
+	}
+
+	for _, e := range res.Resources {
+		if e.ErrorCode != int16(sarama.ErrNoError) {
+			return errors.New(e.ErrorMsg)
+		}
 	}
 
 	return nil
 }
 
-func (c *Client) CreateTopic(t Topic) error {
+func (c *Client) CreateTopic(t Topic, timeout time.Duration) error {
 	broker, err := c.client.Controller()
 	if err != nil {
 		return err
 	}
 
-	timeout := time.Duration(c.config.Timeout) * time.Second
 	log.Printf("[TRACE] Timeout is %v ", timeout)
 
 	req := &sarama.CreateTopicsRequest{
@@ -280,13 +426,12 @@ func (c *Client) CreateTopic(t Topic) error {
 	return err
 }
 
-func (c *Client) AddPartitions(t Topic) error {
+func (c *Client) AddPartitions(t Topic, timeout time.Duration) error {
 	broker, err := c.client.Controller()
 	if err != nil {
 		return err
 	}
 
-	timeout := time.Duration(c.config.Timeout) * time.Second
 	tp := map[string]*sarama.TopicPartition{
 		t.Name: &sarama.TopicPartition{
 			Count: t.Partitions,
@@ -463,6 +608,76 @@ func isPartitionRFChanging(status *sarama.PartitionReplicaReassignmentsStatus) b
 	return len(status.AddingReplicas) != 0 || len(status.RemovingReplicas) != 0
 }
 
+// CancelPartitionReassignments cancels every pending reassignment of the
+// topic's partitions, reverting them to the replicas they had before the
+// reassignment started.
+func (c *Client) CancelPartitionReassignments(topic string, timeout time.Duration) error {
+	if err := c.client.RefreshMetadata(); err != nil {
+		return err
+	}
+
+	partitions, err := c.client.Partitions(topic)
+	if err != nil {
+		return err
+	}
+
+	admin, err := sarama.NewClusterAdminFromClient(c.client)
+	if err != nil {
+		return err
+	}
+
+	statusMap, err := admin.ListPartitionReassignments(topic, partitions)
+	if err != nil {
+		return err
+	}
+
+	req := &sarama.AlterPartitionReassignmentsRequest{
+		TimeoutMs: int32(timeout / time.Millisecond),
+		Version:   0,
+	}
+
+	pending := 0
+	for p, status := range statusMap[topic] {
+		if isPartitionRFChanging(status) {
+			// a nil replica list cancels the reassignment of the partition
+			req.AddBlock(topic, p, nil)
+			pending++
+		}
+	}
+
+	if pending == 0 {
+		log.Printf("[DEBUG] No pending reassignments to cancel for %s", topic)
+		return nil
+	}
+
+	broker, err := c.client.Controller()
+	if err != nil {
+		return err
+	}
+
+	log.Printf("[INFO] Cancelling reassignment of %d partitions of %s", pending, topic)
+	res, err := broker.AlterPartitionReassignments(req)
+	if err != nil {
+		return err
+	}
+
+	if res.ErrorCode != sarama.ErrNoError {
+		return res.ErrorCode
+	}
+
+	// sarama doesn't expose the per-partition errors of the response, so
+	// check that the reassignments are actually gone
+	isRFUpdating, err := c.IsReplicationFactorUpdating(topic)
+	if err != nil {
+		return err
+	}
+	if isRFUpdating {
+		return fmt.Errorf("reassignment of %s is still in progress after cancelling it", topic)
+	}
+
+	return nil
+}
+
 func (client *Client) ReadTopic(name string, refreshMetadata bool) (Topic, error) {
 	c := client.client
 	log.Printf("[INFO] 👋 reading topic '%s' from Kafka: %v", name, refreshMetadata)
@@ -494,15 +709,21 @@ func (client *Client) ReadTopic(name string, refreshMetadata bool) (Topic, error
 			log.Printf("[DEBUG] [%s] %d Partitions Found: %v from Kafka", name, partitionCount, p)
 			topic.Partitions = partitionCount
 
-			r, err := ReplicaCount(c, name, p)
+			counts, err := ReplicaCounts(c, name, p)
 			if err != nil {
 				return topic, err
 			}
 
+			r, uniform := majorityReplicationFactor(counts)
+			if !uniform {
+				log.Printf("[WARN] [%s] Partitions have different replica counts: %v", name, counts)
+				topic.PartitionReplicationFactors = counts
+			}
+
 			log.Printf("[DEBUG] [%s] ReplicationFactor %d from Kafka", name, r)
-			topic.ReplicationFactor = int16(r)
+			topic.ReplicationFactor = r
 
-			configToSave, err := client.topicConfig(name)
+			configToSave, effectiveConfig, err := client.topicConfig(name)
 			if err != nil {
 				log.Printf("[ERROR] [%s] Could not get config for topic %s", name, err)
 				return topic, err
@@ -510,6 +731,16 @@ func (client *Client) ReadTopic(name string, refreshMetadata bool) (Topic, error
 
 			log.Printf("[TRACE] [%s] Config %v from Kafka", name, strPtrMapToStrMap(configToSave))
 			topic.Config = configToSave
+			topic.EffectiveConfig = effectiveConfig
+
+			if client.CanReadTopicIDs() {
+				ids, err := client.TopicIDs([]string{name})
+				if err != nil {
+					// the ID is informational, so it shouldn't stop the topic being read
+					log.Printf("[WARN] [%s] Could not read the topic ID: %s", name, err)
+				}
+				topic.ID = ids[name]
+			}
 			return topic, nil
 		}
 	}
@@ -529,9 +760,11 @@ func (c *Client) versionForKey(apiKey, wantedMaxVersion int) int {
 	return 0
 }
 
-//topicConfig retrives the non-default config map for a topic
-func (c *Client) topicConfig(topic string) (map[string]*string, error) {
+//topicConfig retrives the non-default config map for a topic, along with
+//every config that applies to it
+func (c *Client) topicConfig(topic string) (map[string]*string, []TopicConfigEntry, error) {
 	conf := map[string]*string{}
+	effective := []TopicConfigEntry{}
 	request := &sarama.DescribeConfigsRequest{
 		Version: c.getDescribeConfigAPIVersion(),
 		Resources: []*sarama.ConfigResource{
@@ -544,41 +777,56 @@ func (c *Client) topicConfig(topic string) (map[string]*string, error) {
 
 	broker, err := c.client.Controller()
 	if err != nil {
-		return conf, err
+		return conf, effective, err
 	}
 
 	cr, err := broker.DescribeConfigs(request)
 	if err != nil {
-		return conf, err
+		return conf, effective, err
 	}
 
-	if len(cr.Resources) > 0 && len(cr.Resources[0].Configs) > 0 {
-		for _, tConf := range cr.Resources[0].Configs {
-			v := tConf.Value
-			log.Printf("[TRACE] [%s] %s: %v. Default %v, Source %v, Version %d", topic, tConf.Name, v, tConf.Default, tConf.Source, cr.Version)
+	if len(cr.Resources) > 0 {
+		conf, effective = topicConfigFromResponse(cr.Resources[0], cr.Version)
+	}
 
-			for _, s := range tConf.Synonyms {
-				log.Printf("[TRACE] Syonyms: %v", s)
-			}
+	return conf, effective, nil
+}
 
-			if isDefault(tConf, int(cr.Version)) {
-				continue
-			}
-			conf[tConf.Name] = &v
+// topicConfigFromResponse extracts the non-default config map of a topic, and
+// every config that applies to it, from a DescribeConfigs response
+func topicConfigFromResponse(res *sarama.ResourceResponse, version int16) (map[string]*string, []TopicConfigEntry) {
+	conf := map[string]*string{}
+	effective := []TopicConfigEntry{}
+
+	for _, tConf := range res.Configs {
+		v := tConf.Value
+		log.Printf("[TRACE] [%s] %s: %v. Default %v, Source %v, Version %d", res.Name, tConf.Name, v, tConf.Default, tConf.Source, version)
+
+		for _, s := range tConf.Synonyms {
+			log.Printf("[TRACE] Syonyms: %v", s)
 		}
+
+		effective = append(effective, newTopicConfigEntry(tConf, int(version)))
+
+		if isDefault(tConf, int(version)) {
+			continue
+		}
+		conf[tConf.Name] = &v
 	}
-	return conf, nil
+
+	sort.Slice(effective, func(i, j int) bool { return effective[i].Name < effective[j].Name })
+	return conf, effective
 }
 
 func (c *Client) getDescribeAclsRequestAPIVersion() int16 {
-	return int16(c.versionForKey(29, 1))
+	return int16(c.versionForKey(29, aclMaxRequestVersion))
 }
 func (c *Client) getCreateAclsRequestAPIVersion() int16 {
-	return int16(c.versionForKey(30, 1))
+	return int16(c.versionForKey(30, aclMaxRequestVersion))
 }
 
 func (c *Client) getDeleteAclsRequestAPIVersion() int16 {
-	return int16(c.versionForKey(31, 1))
+	return int16(c.versionForKey(31, aclMaxRequestVersion))
 }
 
 func (c *Client) getDescribeConfigAPIVersion() int16 {
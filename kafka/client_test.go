@@ -1,6 +1,8 @@
 package kafka
 
-import "testing"
+import (
+	"testing"
+)
 
 func Test_NewClient(t *testing.T) {
 	config := &Config{}
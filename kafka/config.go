@@ -9,7 +9,7 @@ import (
 	"log"
 	"time"
 
-	"github.com/Shopify/sarama"
+	"github.com/IBM/sarama"
 )
 
 type Config struct {
@@ -24,6 +24,10 @@ type Config struct {
 	SASLUsername            string
 	SASLPassword            string
 	SASLMechanism           string
+	PinTopicConfigs         bool
+	IgnoreConfigKeys        []string
+	StrictACLLint           bool
+	AllowBroadACLDeletion   bool
 }
 
 func (c *Config) newKafkaConfig() (*sarama.Config, error) {
@@ -179,6 +183,10 @@ func (config *Config) copyWithMaskedSensitiveValues() Config {
 		config.SASLUsername,
 		"*****",
 		config.SASLMechanism,
+		config.PinTopicConfigs,
+		config.IgnoreConfigKeys,
+		config.StrictACLLint,
+		config.AllowBroadACLDeletion,
 	}
 	return copy
 }
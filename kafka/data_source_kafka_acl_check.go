@@ -0,0 +1,120 @@
+package kafka
+
+import (
+	"log"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+func kafkaACLCheckDataSource() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceACLCheckRead,
+		Schema: map[string]*schema.Schema{
+			"principal": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "The principal making the request, e.g. User:alice.",
+			},
+			"host": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "The host the request comes from.",
+			},
+			"operation": {
+				Type:         schema.TypeString,
+				Required:     true,
+				Description:  "The operation requested, e.g. Read.",
+				ValidateFunc: validation.StringInSlice(aclOperations, false),
+			},
+			"resource_type": {
+				Type:         schema.TypeString,
+				Required:     true,
+				Description:  "The type of the resource, e.g. Topic.",
+				ValidateFunc: validation.StringInSlice(aclResourceTypes, false),
+			},
+			"resource_name": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "The name of the resource, kafka-cluster for the cluster.",
+			},
+			"planned_acls": {
+				Type:        schema.TypeSet,
+				Optional:    true,
+				Description: "The IDs of ACLs to evaluate as if they existed, e.g. from kafka_acl or kafka_principal_access.",
+				Elem: &schema.Schema{
+					Type:         schema.TypeString,
+					ValidateFunc: validateACLID,
+				},
+			},
+			"super_users": {
+				Type:        schema.TypeSet,
+				Optional:    true,
+				Description: "The principals of the brokers' super.users setting.",
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"allow_everyone_if_no_acl_found": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Description: "The brokers' allow.everyone.if.no.acl.found setting.",
+			},
+			"allowed": {
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: "Whether the request is allowed.",
+			},
+			"reason": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Why the request is allowed or denied.",
+			},
+			"deciding_acls": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "The IDs of the ACLs that allow or deny the request.",
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+		},
+	}
+}
+
+func dataSourceACLCheckRead(d *schema.ResourceData, meta interface{}) error {
+	req := aclRequest{
+		Principal:    d.Get("principal").(string),
+		Host:         d.Get("host").(string),
+		Operation:    d.Get("operation").(string),
+		ResourceType: d.Get("resource_type").(string),
+		ResourceName: d.Get("resource_name").(string),
+	}
+
+	planned, err := parseACLIDs(d.Get("planned_acls").(*schema.Set))
+	if err != nil {
+		return err
+	}
+
+	client := meta.(*LazyClient)
+	resources, err := client.CachedACLs()
+	if err != nil {
+		return err
+	}
+
+	bindings := aclBindings(resources)
+	for id, a := range planned {
+		bindings[id] = a
+	}
+
+	decision := authorizeACLRequest(req, bindings, setToStrings(d.Get("super_users").(*schema.Set)), d.Get("allow_everyone_if_no_acl_found").(bool))
+	log.Printf("[DEBUG] %s %s on %s %s from %s: %s", req.Principal, req.Operation, req.ResourceType, req.ResourceName, req.Host, decision.Reason)
+
+	errSet := errSetter{d: d}
+	errSet.Set("allowed", decision.Allowed)
+	errSet.Set("reason", decision.Reason)
+	errSet.Set("deciding_acls", aclIDs(decision.ACLs))
+	if errSet.err != nil {
+		return errSet.err
+	}
+
+	d.SetId(strings.Join([]string{req.Principal, req.Host, req.Operation, req.ResourceType, req.ResourceName}, "|"))
+	return nil
+}
@@ -0,0 +1,55 @@
+package kafka
+
+import (
+	"fmt"
+	"testing"
+
+	uuid "github.com/hashicorp/go-uuid"
+	r "github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAcc_ACLCheckData(t *testing.T) {
+	u, err := uuid.GenerateUUID()
+	if err != nil {
+		t.Fatal(err)
+	}
+	topicName := fmt.Sprintf("orders-%s", u)
+	bs := testBootstrapServers[0]
+
+	r.Test(t, r.TestCase{
+		ProviderFactories: overrideProviderFactory(),
+		PreCheck:          func() { testAccPreCheck(t) },
+		Steps: []r.TestStep{
+			{
+				Config: cfg(t, bs, fmt.Sprintf(testDataSourceACLCheck_config, topicName)),
+				Check: r.ComposeTestCheckFunc(
+					r.TestCheckResourceAttr("data.kafka_acl_check.read", "allowed", "true"),
+					r.TestCheckResourceAttr("data.kafka_acl_check.read", "reason", aclDecisionAllowed),
+					r.TestCheckResourceAttr("data.kafka_acl_check.read", "deciding_acls.#", "1"),
+					r.TestCheckResourceAttr("data.kafka_acl_check.write", "allowed", "false"),
+					r.TestCheckResourceAttr("data.kafka_acl_check.write", "reason", aclDecisionNoMatchingACL),
+				),
+			},
+		},
+	})
+}
+
+const testDataSourceACLCheck_config = `
+data "kafka_acl_check" "read" {
+  principal     = "User:alice"
+  host          = "10.0.0.5"
+  operation     = "Read"
+  resource_type = "Topic"
+  resource_name = "%[1]s"
+  planned_acls  = ["User:alice|*|Read|Allow|Topic|%[1]s|Literal"]
+}
+
+data "kafka_acl_check" "write" {
+  principal     = "User:alice"
+  host          = "10.0.0.5"
+  operation     = "Write"
+  resource_type = "Topic"
+  resource_name = "%[1]s"
+  planned_acls  = ["User:alice|*|Read|Allow|Topic|%[1]s|Literal"]
+}
+`
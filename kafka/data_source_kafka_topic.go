@@ -16,6 +16,11 @@ func kafkaTopicDataSource() *schema.Resource {
 				Required:    true,
 				Description: "The name of the topic.",
 			},
+			"topic_id": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The unique ID Kafka assigned to the topic, only set by Kafka >= 2.8.0.",
+			},
 			"partitions": {
 				Type:        schema.TypeInt,
 				Computed:    true,
@@ -32,6 +37,13 @@ func kafkaTopicDataSource() *schema.Resource {
 				Description: "A map of string k/v attributes.",
 				Elem:        schema.TypeString,
 			},
+			"effective_config": effectiveConfigSchema(),
+			"partition_replication_factors": {
+				Type:        schema.TypeMap,
+				Computed:    true,
+				Description: "The number of replicas of each partition, only set when they differ across partitions.",
+				Elem:        &schema.Schema{Type: schema.TypeInt},
+			},
 		},
 	}
 }
@@ -58,9 +70,12 @@ func dataSourceTopicRead(d *schema.ResourceData, meta interface{}) error {
 	log.Printf("[DEBUG] Setting the state from Kafka %v", topic)
 	errSet := errSetter{d: d}
 	errSet.Set("name", topic.Name)
+	errSet.Set("topic_id", topic.ID)
 	errSet.Set("partitions", topic.Partitions)
 	errSet.Set("replication_factor", topic.ReplicationFactor)
 	errSet.Set("config", topic.Config)
+	errSet.Set("effective_config", flattenEffectiveConfig(topic.EffectiveConfig))
+	errSet.Set("partition_replication_factors", flattenReplicationFactors(topic.PartitionReplicationFactors))
 
 	// Set the id to the name
 	d.SetId(name)
@@ -1,11 +1,12 @@
 package kafka
 
 import (
+	"errors"
 	"fmt"
 	"log"
 	"strings"
 
-	"github.com/Shopify/sarama"
+	"github.com/IBM/sarama"
 )
 
 type ACL struct {
@@ -67,6 +68,74 @@ func tfToAclCreation(s StringlyTypedACL) (*sarama.AclCreation, error) {
 
 const unknownConversion = -1
 
+// The resource types and operations newer than the ones sarama knows about
+const (
+	aclResourceUser            sarama.AclResourceType = 7
+	aclOperationCreateTokens   sarama.AclOperation    = 13
+	aclOperationDescribeTokens sarama.AclOperation    = 14
+)
+
+// The values of the ACL arguments that can be used to create ACLs. The User
+// resource type and the CreateTokens and DescribeTokens operations need
+// CreateAcls and DescribeAcls v3, which sarama can't send, so they are left out.
+var (
+	aclResourceTypes = []string{"Topic", "Group", "Cluster", "TransactionalID", "DelegationToken"}
+	aclOperations    = []string{
+		"All", "Read", "Write", "Create", "Delete", "Alter", "Describe", "ClusterAction",
+		"DescribeConfigs", "AlterConfigs", "IdempotentWrite",
+	}
+	aclPermissionTypes = []string{"Allow", "Deny"}
+	aclPatternTypes    = []string{"Literal", "Prefixed"}
+)
+
+// aclResourceTypeVersions and aclOperationVersions are the CreateAcls and
+// DescribeAcls versions from which brokers know about the resource types and
+// operations added after the first ACL APIs
+var (
+	aclResourceTypeVersions = map[string]int{
+		"DelegationToken": 1,
+		"User":            3,
+	}
+	aclOperationVersions = map[string]int{
+		"CreateTokens":   3,
+		"DescribeTokens": 3,
+	}
+)
+
+// aclVersion returns the CreateAcls and DescribeAcls version the brokers need
+// to support the ACL
+func aclVersion(s StringlyTypedACL) int {
+	v := aclResourceTypeVersions[s.Resource.Type]
+	if o := aclOperationVersions[s.ACL.Operation]; o > v {
+		v = o
+	}
+	return v
+}
+
+// aclMaxRequestVersion is the newest version of CreateAcls and DescribeAcls
+// sarama sends
+const aclMaxRequestVersion = 1
+
+// supportedACLVersion is the version both CreateAcls and DescribeAcls are
+// sent with: the newest one both the brokers and sarama support.
+func (c *Client) supportedACLVersion() int {
+	create := int(c.getCreateAclsRequestAPIVersion())
+	describe := int(c.getDescribeAclsRequestAPIVersion())
+	if describe < create {
+		return describe
+	}
+	return create
+}
+
+// checkACLSupported returns an error when the brokers don't know about the
+// resource type or the operation of the ACL
+func (c *Client) checkACLSupported(s StringlyTypedACL) error {
+	if v := aclVersion(s); c.supportedACLVersion() < v {
+		return fmt.Errorf("ACL %s needs CreateAcls and DescribeAcls v%d, but they are sent with v%d", s, v, c.supportedACLVersion())
+	}
+	return nil
+}
+
 func tfToAclFilter(s StringlyTypedACL) (sarama.AclFilter, error) {
 	f := sarama.AclFilter{
 		Principal:    &s.ACL.Principal,
@@ -129,25 +198,25 @@ func resourcePatternToString(p sarama.AclResourcePatternType) string {
 	return "unknownConversion"
 }
 
-func (c *Client) DeleteACL(s StringlyTypedACL) error {
+// DeleteACL deletes the binding s and returns the bindings that were deleted.
+// Filters with Any or Match fields delete every binding they match, so the
+// matching bindings are described first, and the deletion is refused when
+// there are others than s, unless allow_broad_acl_deletion is set.
+func (c *Client) DeleteACL(s StringlyTypedACL) ([]StringlyTypedACL, error) {
 	log.Printf("[INFO] Deleting ACL %v", s)
+	defer c.aclCache.invalidate()
+
 	broker, err := c.client.Controller()
 	if err != nil {
-		return err
-	}
-	aclsBeforeDelete, err := c.ListACLs()
-	if err != nil {
-		return fmt.Errorf("Unable to list acls before deleting -- can't be sure we're doing the right thing: %s", err)
-	}
-
-	log.Printf("[INFO] Acls before deletion: %d", len(aclsBeforeDelete))
-	for _, acl := range aclsBeforeDelete {
-		log.Printf("[DEBUG] ACL: %v", acl)
+		return nil, err
 	}
 
 	filter, err := tfToAclFilter(s)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	if err := c.checkACLDeletion(s); err != nil {
+		return nil, err
 	}
 
 	req := &sarama.DeleteAclsRequest{
@@ -157,26 +226,133 @@ func (c *Client) DeleteACL(s StringlyTypedACL) error {
 
 	res, err := broker.DeleteAcls(req)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	matchingAclCount := 0
-
+	deleted := []StringlyTypedACL{}
 	for _, r := range res.FilterResponses {
-		matchingAclCount += len(r.MatchingAcls)
 		if r.Err != sarama.ErrNoError {
-			return r.Err
+			return deleted, aclError(r.Err, r.ErrMsg)
+		}
+		for _, m := range r.MatchingAcls {
+			if m.Err != sarama.ErrNoError {
+				return deleted, aclError(m.Err, m.ErrMsg)
+			}
+			d := matchingACLBinding(m)
+			log.Printf("[INFO] Deleted ACL %s", d)
+			deleted = append(deleted, d)
 		}
 	}
 
-	if matchingAclCount == 0 {
-		return fmt.Errorf("There were no acls matching this filter")
+	if len(deleted) == 0 {
+		return deleted, errNoMatchingACLs
+	}
+	return deleted, nil
+}
+
+// checkACLDeletion describes the bindings the filter of s matches before it's
+// used to delete them, refusing to delete other bindings than s unless
+// allow_broad_acl_deletion is set. Exact filters can only match s, so they
+// aren't described.
+func (c *Client) checkACLDeletion(s StringlyTypedACL) error {
+	if isExactACLFilter(s) {
+		return nil
+	}
+
+	matching, err := c.DescribeACLs(s)
+	if err != nil {
+		return fmt.Errorf("Unable to describe the ACLs matching %s before deleting -- can't be sure we're doing the right thing: %s", s, err)
+	}
+	if others := otherBindings(s, aclBindings(matching)); len(others) > 0 {
+		if c.config == nil || !c.config.AllowBroadACLDeletion {
+			return fmt.Errorf("refusing to delete ACL %s, its filter also matches %s; set allow_broad_acl_deletion on the provider to delete them all", s, strings.Join(others, ", "))
+		}
+		log.Printf("[WARN] Deleting ACL %s also deletes %s", s, strings.Join(others, ", "))
 	}
 	return nil
 }
 
+// otherBindings returns the IDs of the bindings that aren't s
+func otherBindings(s StringlyTypedACL, bindings map[string]StringlyTypedACL) []string {
+	others := []string{}
+	for _, id := range aclIDs(bindings) {
+		if id != s.String() {
+			others = append(others, id)
+		}
+	}
+	return others
+}
+
+func aclsByID(acls []StringlyTypedACL) map[string]StringlyTypedACL {
+	byID := map[string]StringlyTypedACL{}
+	for _, a := range acls {
+		byID[a.String()] = a
+	}
+	return byID
+}
+
+// isExactACLFilter returns whether the filter built from s can only match the
+// binding s itself
+func isExactACLFilter(s StringlyTypedACL) bool {
+	return stringInSlice(s.ACL.Operation, aclOperations) &&
+		stringInSlice(s.ACL.PermissionType, aclPermissionTypes) &&
+		stringInSlice(s.Resource.Type, aclResourceTypes) &&
+		stringInSlice(s.Resource.PatternTypeFilter, aclPatternTypes)
+}
+
+func matchingACLBinding(m *sarama.MatchingAcl) StringlyTypedACL {
+	return StringlyTypedACL{
+		ACL: ACL{
+			Principal:      m.Principal,
+			Host:           m.Host,
+			Operation:      ACLOperationToString(m.Operation),
+			PermissionType: ACLPermissionTypeToString(m.PermissionType),
+		},
+		Resource: Resource{
+			Type:              ACLResourceToString(m.ResourceType),
+			Name:              m.ResourceName,
+			PatternTypeFilter: resourcePatternToString(m.ResourcePatternType),
+		},
+	}
+}
+
+var errNoMatchingACLs = errors.New("There were no acls matching this filter")
+
+// ReplaceACL swaps a binding for another one, creating the new binding before
+// deleting the old one. The new binding is removed again when the old one
+// can't be deleted, unless it existed before.
+func (c *Client) ReplaceACL(old, new StringlyTypedACL) error {
+	resources, err := c.DescribeACLs(new)
+	if err != nil {
+		return err
+	}
+	existed, _ := matchACL(new, resources)
+
+	if err := c.CreateACL(new); err != nil {
+		return err
+	}
+
+	_, err = c.DeleteACL(old)
+	if err == nil || errors.Is(err, errNoMatchingACLs) {
+		// a binding that is already gone doesn't need deleting
+		return nil
+	}
+	if existed {
+		log.Printf("[WARN] Could not delete ACL %s, keeping %s as it existed before: %s", old, new, err)
+		return fmt.Errorf("could not delete ACL %s: %w", old, err)
+	}
+
+	log.Printf("[WARN] Could not delete ACL %s, removing %s again: %s", old, new, err)
+	if _, rerr := c.DeleteACL(new); rerr != nil {
+		return fmt.Errorf("could not delete ACL %s: %s; removing the new ACL %s also failed: %s", old, err, new, rerr)
+	}
+	return fmt.Errorf("could not delete ACL %s: %w", old, err)
+}
+
 func (c *Client) CreateACL(s StringlyTypedACL) error {
 	log.Printf("[DEBUG] Creating ACL %s", s)
+	defer c.aclCache.invalidate()
+
 	broker, err := c.client.Controller()
 	if err != nil {
 		return err
@@ -186,6 +362,9 @@ func (c *Client) CreateACL(s StringlyTypedACL) error {
 	if err != nil {
 		return err
 	}
+	if err := c.checkACLSupported(s); err != nil {
+		return err
+	}
 	req := &sarama.CreateAclsRequest{
 		Version:      c.getCreateAclsRequestAPIVersion(),
 		AclCreations: []*sarama.AclCreation{ac},
@@ -220,6 +399,10 @@ func stringToACLResource(in string) sarama.AclResourceType {
 		return sarama.AclResourceCluster
 	case "TransactionalID":
 		return sarama.AclResourceTransactionalID
+	case "DelegationToken":
+		return sarama.AclResourceDelegationToken
+	case "User":
+		return aclResourceUser
 	}
 	return unknownConversion
 }
@@ -238,6 +421,10 @@ func ACLResourceToString(in sarama.AclResourceType) string {
 		return "Cluster"
 	case sarama.AclResourceTransactionalID:
 		return "TransactionalID"
+	case sarama.AclResourceDelegationToken:
+		return "DelegationToken"
+	case aclResourceUser:
+		return "User"
 	}
 	return "unknownConversion"
 }
@@ -270,6 +457,10 @@ func stringToOperation(in string) sarama.AclOperation {
 		return sarama.AclOperationAlterConfigs
 	case "IdempotentWrite":
 		return sarama.AclOperationIdempotentWrite
+	case "CreateTokens":
+		return aclOperationCreateTokens
+	case "DescribeTokens":
+		return aclOperationDescribeTokens
 	}
 	return unknownConversion
 }
@@ -303,6 +494,10 @@ func ACLOperationToString(in sarama.AclOperation) string {
 		return "AlterConfigs"
 	case sarama.AclOperationIdempotentWrite:
 		return "IdempotentWrite"
+	case aclOperationCreateTokens:
+		return "CreateTokens"
+	case aclOperationDescribeTokens:
+		return "DescribeTokens"
 	}
 	return "unknownConversion"
 }
@@ -343,16 +538,57 @@ func (c *Client) DescribeACLs(s StringlyTypedACL) ([]*sarama.ResourceAcls, error
 		return nil, err
 	}
 
-	broker, err := c.client.Controller()
+	err = c.client.RefreshMetadata()
 	if err != nil {
 		return nil, err
 	}
-	err = c.client.RefreshMetadata()
+
+	return c.describeACLs(aclFilter)
+}
+
+// FindACLs returns the ACLs of the principal on the resources with the type and
+// name of s, whatever their pattern type, host, operation or permission type,
+// so that a binding that changed can be told apart from a missing one. A
+// single filtered DescribeAcls request reads them.
+func (c *Client) FindACLs(s StringlyTypedACL) ([]*sarama.ResourceAcls, error) {
+	aclFilter, err := findACLsFilter(s)
+	if err != nil {
+		return nil, err
+	}
+
+	found, err := c.describeACLs(aclFilter)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("[DEBUG] Found ACLs on %d resources matching %s", len(found), s)
+	return found, nil
+}
+
+// findACLsFilter returns the filter of the ACLs FindACLs returns
+func findACLsFilter(s StringlyTypedACL) (sarama.AclFilter, error) {
+	f, err := tfToAclFilter(s)
+	if err != nil {
+		return f, err
+	}
+
+	f.Host = nil
+	f.Operation = sarama.AclOperationAny
+	f.PermissionType = sarama.AclPermissionAny
+	// Any matches the literal and prefixed resources with exactly that
+	// name, unlike Match which also matches the ones covering it
+	f.ResourcePatternTypeFilter = sarama.AclPatternAny
+	return f, nil
+}
+
+func (c *Client) describeACLs(aclFilter sarama.AclFilter) ([]*sarama.ResourceAcls, error) {
+	broker, err := c.client.Controller()
 	if err != nil {
 		return nil, err
 	}
 
 	r := &sarama.DescribeAclsRequest{
+		Version:   int(c.getDescribeAclsRequestAPIVersion()),
 		AclFilter: aclFilter,
 	}
 
@@ -361,13 +597,54 @@ func (c *Client) DescribeACLs(s StringlyTypedACL) ([]*sarama.ResourceAcls, error
 		return nil, err
 	}
 
-	if err == nil {
-		if aclsR.Err != sarama.ErrNoError {
-			return nil, fmt.Errorf("%s", aclsR.Err)
-		}
+	if aclsR.Err != sarama.ErrNoError {
+		return nil, fmt.Errorf("%s", aclsR.Err)
+	}
+
+	return aclsR.ResourceAcls, nil
+}
+
+// DescribePrincipalACLs returns every ACL of the principal, whatever its
+// resource, operation or host
+func (c *Client) DescribePrincipalACLs(principal string) ([]*sarama.ResourceAcls, error) {
+	broker, err := c.client.Controller()
+	if err != nil {
+		return nil, err
+	}
+
+	r := &sarama.DescribeAclsRequest{
+		Version: int(c.getDescribeAclsRequestAPIVersion()),
+		AclFilter: sarama.AclFilter{
+			Principal:                 &principal,
+			ResourceType:              sarama.AclResourceAny,
+			ResourcePatternTypeFilter: sarama.AclPatternAny,
+			PermissionType:            sarama.AclPermissionAny,
+			Operation:                 sarama.AclOperationAny,
+		},
 	}
 
-	return aclsR.ResourceAcls, err
+	log.Printf("[DEBUG] Describing the ACLs of %s", principal)
+	res, err := broker.DescribeAcls(r)
+	if err != nil {
+		return nil, err
+	}
+	if res.Err != sarama.ErrNoError {
+		return nil, res.Err
+	}
+
+	return res.ResourceAcls, nil
+}
+
+// listedACLResourceTypes returns the resource types whose ACLs ListACLs
+// describes, leaving out the ones the brokers don't know about
+func (c *Client) listedACLResourceTypes() []sarama.AclResourceType {
+	types := []sarama.AclResourceType{}
+	for _, t := range aclResourceTypes {
+		if c.supportedACLVersion() >= aclResourceTypeVersions[t] {
+			types = append(types, stringToACLResource(t))
+		}
+	}
+	return types
 }
 
 func (c *Client) ListACLs() ([]*sarama.ResourceAcls, error) {
@@ -377,43 +654,17 @@ func (c *Client) ListACLs() ([]*sarama.ResourceAcls, error) {
 		return nil, err
 	}
 
-	allResources := []*sarama.DescribeAclsRequest{
-		&sarama.DescribeAclsRequest{
-			Version: int(c.getDescribeAclsRequestAPIVersion()),
-			AclFilter: sarama.AclFilter{
-				ResourceType:              sarama.AclResourceTopic,
-				ResourcePatternTypeFilter: sarama.AclPatternAny,
-				PermissionType:            sarama.AclPermissionAny,
-				Operation:                 sarama.AclOperationAny,
-			},
-		},
-		&sarama.DescribeAclsRequest{
-			Version: int(c.getDescribeAclsRequestAPIVersion()),
-			AclFilter: sarama.AclFilter{
-				ResourceType:              sarama.AclResourceGroup,
-				ResourcePatternTypeFilter: sarama.AclPatternAny,
-				PermissionType:            sarama.AclPermissionAny,
-				Operation:                 sarama.AclOperationAny,
-			},
-		},
-		&sarama.DescribeAclsRequest{
-			Version: int(c.getDescribeAclsRequestAPIVersion()),
-			AclFilter: sarama.AclFilter{
-				ResourceType:              sarama.AclResourceCluster,
-				ResourcePatternTypeFilter: sarama.AclPatternAny,
-				PermissionType:            sarama.AclPermissionAny,
-				Operation:                 sarama.AclOperationAny,
-			},
-		},
-		&sarama.DescribeAclsRequest{
+	allResources := []*sarama.DescribeAclsRequest{}
+	for _, resourceType := range c.listedACLResourceTypes() {
+		allResources = append(allResources, &sarama.DescribeAclsRequest{
 			Version: int(c.getDescribeAclsRequestAPIVersion()),
 			AclFilter: sarama.AclFilter{
-				ResourceType:              sarama.AclResourceTransactionalID,
+				ResourceType:              resourceType,
 				ResourcePatternTypeFilter: sarama.AclPatternAny,
 				PermissionType:            sarama.AclPermissionAny,
 				Operation:                 sarama.AclOperationAny,
 			},
-		},
+		})
 	}
 	res := []*sarama.ResourceAcls{}
 
@@ -437,3 +688,24 @@ func (c *Client) ListACLs() ([]*sarama.ResourceAcls, error) {
 
 	return res, err
 }
+
+// matchACL looks for the binding of a among the ACLs returned by the brokers,
+// comparing every field of it. When it isn't there, the near misses are the
+// bindings that only differ from it in host, permission type or pattern type.
+func matchACL(a StringlyTypedACL, resources []*sarama.ResourceAcls) (found bool, nearMisses []StringlyTypedACL) {
+	bindings := aclBindings(resources)
+	if _, ok := bindings[a.String()]; ok {
+		return true, nil
+	}
+
+	for _, id := range aclIDs(bindings) {
+		b := bindings[id]
+		if b.ACL.Principal == a.ACL.Principal &&
+			b.ACL.Operation == a.ACL.Operation &&
+			b.Resource.Type == a.Resource.Type &&
+			b.Resource.Name == a.Resource.Name {
+			nearMisses = append(nearMisses, b)
+		}
+	}
+	return false, nearMisses
+}
@@ -0,0 +1,268 @@
+package kafka
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/IBM/sarama"
+)
+
+// testDescribeACLsResponse is what a broker returns for the ACLs of the
+// User:Alice and User:Bob principals on the syslog topic
+var testDescribeACLsResponse = []*sarama.ResourceAcls{
+	{
+		Resource: sarama.Resource{
+			ResourceType:        sarama.AclResourceTopic,
+			ResourceName:        "syslog",
+			ResourcePatternType: sarama.AclPatternLiteral,
+		},
+		Acls: []*sarama.Acl{
+			{Principal: "User:Alice", Host: "*", Operation: sarama.AclOperationRead, PermissionType: sarama.AclPermissionAllow},
+			{Principal: "User:Alice", Host: "10.0.0.1", Operation: sarama.AclOperationWrite, PermissionType: sarama.AclPermissionAllow},
+			{Principal: "User:Bob", Host: "*", Operation: sarama.AclOperationDescribe, PermissionType: sarama.AclPermissionAllow},
+		},
+	},
+	{
+		Resource: sarama.Resource{
+			ResourceType:        sarama.AclResourceTopic,
+			ResourceName:        "syslog",
+			ResourcePatternType: sarama.AclPatternPrefixed,
+		},
+		Acls: []*sarama.Acl{
+			{Principal: "User:Alice", Host: "*", Operation: sarama.AclOperationDescribe, PermissionType: sarama.AclPermissionAllow},
+			{Principal: "User:Alice", Host: "*", Operation: sarama.AclOperationWrite, PermissionType: sarama.AclPermissionDeny},
+		},
+	},
+	{
+		Resource: sarama.Resource{
+			ResourceType:        sarama.AclResourceGroup,
+			ResourceName:        "syslog",
+			ResourcePatternType: sarama.AclPatternLiteral,
+		},
+		Acls: []*sarama.Acl{
+			{Principal: "User:Alice", Host: "*", Operation: sarama.AclOperationDescribe, PermissionType: sarama.AclPermissionAllow},
+		},
+	},
+}
+
+func Test_MatchACL(t *testing.T) {
+	found, nearMisses := matchACL(testACL("Read"), testDescribeACLsResponse)
+	if !found || len(nearMisses) != 0 {
+		t.Errorf("Expected an exact match, got %v and near misses %v", found, nearMisses)
+	}
+
+	// an ACL of another principal with the same operation doesn't count
+	bob := testACL("Read")
+	bob.ACL.Principal = "User:Bob"
+	found, nearMisses = matchACL(bob, testDescribeACLsResponse)
+	if found || len(nearMisses) != 0 {
+		t.Errorf("Expected no match for User:Bob, got %v and near misses %v", found, nearMisses)
+	}
+}
+
+func Test_MatchACLNearMisses(t *testing.T) {
+	for name, tc := range map[string]struct {
+		acl      func(a *StringlyTypedACL)
+		expected []string
+	}{
+		"host": {
+			acl: func(a *StringlyTypedACL) {
+				a.ACL.Operation = "Write"
+				a.ACL.Host = "10.0.0.2"
+			},
+			expected: []string{
+				"User:Alice|*|Write|Deny|Topic|syslog|Prefixed",
+				"User:Alice|10.0.0.1|Write|Allow|Topic|syslog|Literal",
+			},
+		},
+		"permission type": {
+			acl: func(a *StringlyTypedACL) {
+				a.ACL.PermissionType = "Deny"
+			},
+			expected: []string{"User:Alice|*|Read|Allow|Topic|syslog|Literal"},
+		},
+		"pattern type": {
+			acl: func(a *StringlyTypedACL) {
+				a.ACL.Operation = "Describe"
+			},
+			expected: []string{"User:Alice|*|Describe|Allow|Topic|syslog|Prefixed"},
+		},
+		"resource type": {
+			acl: func(a *StringlyTypedACL) {
+				a.ACL.Operation = "Describe"
+				a.Resource.Type = "TransactionalID"
+			},
+			expected: nil,
+		},
+	} {
+		a := testACL("Read")
+		tc.acl(&a)
+
+		found, nearMisses := matchACL(a, testDescribeACLsResponse)
+		if found {
+			t.Errorf("%s: expected %s not to match", name, a)
+		}
+
+		got := []string{}
+		for _, m := range nearMisses {
+			got = append(got, m.String())
+		}
+		if len(got) != 0 || len(tc.expected) != 0 {
+			if !reflect.DeepEqual(got, tc.expected) {
+				t.Errorf("%s: got near misses %v, expected %v", name, got, tc.expected)
+			}
+		}
+	}
+}
+
+func Test_ACLConversions(t *testing.T) {
+	for _, resourceType := range aclResourceTypes {
+		if got := ACLResourceToString(stringToACLResource(resourceType)); got != resourceType {
+			t.Errorf("Converting resource type %s back and forth gave %s", resourceType, got)
+		}
+	}
+	for _, operation := range aclOperations {
+		if got := ACLOperationToString(stringToOperation(operation)); got != operation {
+			t.Errorf("Converting operation %s back and forth gave %s", operation, got)
+		}
+	}
+
+	if got := stringToACLResource("User"); got != 7 {
+		t.Errorf("Expected the User resource type to be 7, got %d", got)
+	}
+	if got := stringToOperation("DescribeTokens"); got != 14 {
+		t.Errorf("Expected the DescribeTokens operation to be 14, got %d", got)
+	}
+}
+
+func Test_CheckACLSupported(t *testing.T) {
+	user := testACL("CreateTokens")
+	user.Resource.Type = "User"
+
+	client := &Client{supportedAPIs: map[int]int{29: 1, 30: 1}}
+	if err := client.checkACLSupported(testACL("Read")); err != nil {
+		t.Errorf("Expected topic ACLs to be supported, got %s", err)
+	}
+	if err := client.checkACLSupported(user); err == nil {
+		t.Errorf("Expected User ACLs not to be supported with CreateAcls v1")
+	}
+
+	expected := []sarama.AclResourceType{
+		sarama.AclResourceTopic,
+		sarama.AclResourceGroup,
+		sarama.AclResourceCluster,
+		sarama.AclResourceTransactionalID,
+		sarama.AclResourceDelegationToken,
+	}
+	if got := client.listedACLResourceTypes(); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Got listed resource types %v, expected %v", got, expected)
+	}
+
+	// brokers supporting v3 still get v1, which can't encode User ACLs
+	client.supportedAPIs = map[int]int{29: 3, 30: 3}
+	if err := client.checkACLSupported(user); err == nil {
+		t.Errorf("Expected User ACLs not to be supported with CreateAcls v1")
+	}
+	if got := client.listedACLResourceTypes(); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Got listed resource types %v, expected %v", got, expected)
+	}
+
+	client.supportedAPIs = map[int]int{29: 0, 30: 1}
+	if err := client.checkACLSupported(testACL("Read")); err != nil {
+		t.Errorf("Expected topic ACLs to be supported, got %s", err)
+	}
+	if got := client.listedACLResourceTypes(); len(got) != len(aclResourceTypes)-1 {
+		t.Errorf("Expected DelegationToken ACLs not to be listed with DescribeAcls v0, got %v", got)
+	}
+}
+
+func Test_IsExactACLFilter(t *testing.T) {
+	if !isExactACLFilter(testACL("Read")) {
+		t.Errorf("Expected the filter of %s to be exact", testACL("Read"))
+	}
+
+	for _, broaden := range []func(a *StringlyTypedACL){
+		func(a *StringlyTypedACL) { a.ACL.Operation = "Any" },
+		func(a *StringlyTypedACL) { a.ACL.PermissionType = "Any" },
+		func(a *StringlyTypedACL) { a.Resource.Type = "Any" },
+		func(a *StringlyTypedACL) { a.Resource.PatternTypeFilter = "Any" },
+		func(a *StringlyTypedACL) { a.Resource.PatternTypeFilter = "Match" },
+	} {
+		a := testACL("Read")
+		broaden(&a)
+		if isExactACLFilter(a) {
+			t.Errorf("Expected the filter of %s to match other ACLs", a)
+		}
+	}
+}
+
+func Test_CheckACLDeletion(t *testing.T) {
+	// an exact filter can only match itself, so it's deleted without being
+	// described first
+	client := &Client{config: &Config{}}
+	if err := client.checkACLDeletion(testACL("Read")); err != nil {
+		t.Errorf("Expected %s to be deleted, got %s", testACL("Read"), err)
+	}
+}
+
+func Test_OtherBindings(t *testing.T) {
+	broad := testACL("Any")
+	read := testACL("Read")
+	write := testACL("Write")
+
+	// the bindings a DescribeAcls request with the filter of broad returns
+	matching := aclBindings(testDescribeACLsResponse[:1])
+
+	expected := []string{
+		"User:Alice|*|Read|Allow|Topic|syslog|Literal",
+		"User:Alice|10.0.0.1|Write|Allow|Topic|syslog|Literal",
+		"User:Bob|*|Describe|Allow|Topic|syslog|Literal",
+	}
+	if got := otherBindings(broad, matching); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Got %v, expected %v", got, expected)
+	}
+
+	if got := otherBindings(read, aclsByID([]StringlyTypedACL{read})); len(got) != 0 {
+		t.Errorf("Expected no other bindings than %s, got %v", read, got)
+	}
+	if got := otherBindings(read, aclsByID([]StringlyTypedACL{read, write})); !reflect.DeepEqual(got, []string{write.String()}) {
+		t.Errorf("Expected %s to be the only other binding, got %v", write, got)
+	}
+}
+
+func Test_MatchingACLBinding(t *testing.T) {
+	m := &sarama.MatchingAcl{
+		Resource: testDescribeACLsResponse[1].Resource,
+		Acl:      *testDescribeACLsResponse[1].Acls[1],
+	}
+	expected := "User:Alice|*|Write|Deny|Topic|syslog|Prefixed"
+	if got := matchingACLBinding(m).String(); got != expected {
+		t.Errorf("Got %s, expected %s", got, expected)
+	}
+}
+
+func Test_FindACLsFilter(t *testing.T) {
+	s := testACL("Read")
+	f, err := findACLsFilter(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if f.ResourceType != sarama.AclResourceTopic || f.ResourceName == nil || *f.ResourceName != s.Resource.Name {
+		t.Errorf("Expected a filter on the topic %s, got %+v", s.Resource.Name, f)
+	}
+	if f.Principal == nil || *f.Principal != s.ACL.Principal {
+		t.Errorf("Expected a filter on the principal %s, got %v", s.ACL.Principal, f.Principal)
+	}
+	if f.Host != nil || f.Operation != sarama.AclOperationAny || f.PermissionType != sarama.AclPermissionAny {
+		t.Errorf("Expected any host, operation and permission type to match, got %+v", f)
+	}
+	if f.ResourcePatternTypeFilter != sarama.AclPatternAny {
+		t.Errorf("Expected any pattern type to match, got %v", f.ResourcePatternTypeFilter)
+	}
+
+	s.ACL.Operation = "Fly"
+	if _, err := findACLsFilter(s); err == nil {
+		t.Errorf("Expected an error for an unknown operation")
+	}
+}
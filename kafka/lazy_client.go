@@ -5,15 +5,17 @@ import (
 	"fmt"
 	"log"
 	"sync"
+	"time"
 
-	"github.com/Shopify/sarama"
+	"github.com/IBM/sarama"
 )
 
 type LazyClient struct {
-	once    sync.Once
-	initErr error
-	inner   *Client
-	Config  *Config
+	once         sync.Once
+	initErr      error
+	inner        *Client
+	Config       *Config
+	lintWarnings aclLintWarnings
 }
 
 func (c *LazyClient) init() error {
@@ -58,12 +60,12 @@ func (c *LazyClient) checkTLSConfig() error {
 	return conn.Handshake()
 }
 
-func (c *LazyClient) CreateTopic(t Topic) error {
+func (c *LazyClient) CreateTopic(t Topic, timeout time.Duration) error {
 	err := c.init()
 	if err != nil {
 		return err
 	}
-	return c.inner.CreateTopic(t)
+	return c.inner.CreateTopic(t, timeout)
 }
 
 func (c *LazyClient) ReadTopic(name string, refresh_metadata bool) (Topic, error) {
@@ -74,28 +76,52 @@ func (c *LazyClient) ReadTopic(name string, refresh_metadata bool) (Topic, error
 	return c.inner.ReadTopic(name, refresh_metadata)
 }
 
-func (c *LazyClient) UpdateTopic(t Topic) error {
+func (c *LazyClient) Topics() ([]string, error) {
+	err := c.init()
+	if err != nil {
+		return nil, err
+	}
+	return c.inner.Topics(), nil
+}
+
+func (c *LazyClient) AlterTopicConfig(topic string, set map[string]*string, remove []string) error {
+	err := c.init()
+	if err != nil {
+		return err
+	}
+	return c.inner.AlterTopicConfig(topic, set, remove)
+}
+
+func (c *LazyClient) TopicDeletionEnabled() (bool, error) {
+	err := c.init()
+	if err != nil {
+		return false, err
+	}
+	return c.inner.TopicDeletionEnabled()
+}
+
+func (c *LazyClient) PurgeTopic(topic string, timeout time.Duration) error {
 	err := c.init()
 	if err != nil {
 		return err
 	}
-	return c.inner.UpdateTopic(t)
+	return c.inner.PurgeTopic(topic, timeout)
 }
 
-func (c *LazyClient) DeleteTopic(t string) error {
+func (c *LazyClient) DeleteTopic(t string, timeout time.Duration) error {
 	err := c.init()
 	if err != nil {
 		return err
 	}
-	return c.inner.DeleteTopic(t)
+	return c.inner.DeleteTopic(t, timeout)
 }
 
-func (c *LazyClient) AddPartitions(t Topic) error {
+func (c *LazyClient) AddPartitions(t Topic, timeout time.Duration) error {
 	err := c.init()
 	if err != nil {
 		return err
 	}
-	return c.inner.AddPartitions(t)
+	return c.inner.AddPartitions(t, timeout)
 }
 
 func (c *LazyClient) CanAlterReplicationFactor() (bool, error) {
@@ -122,6 +148,22 @@ func (c *LazyClient) IsReplicationFactorUpdating(topic string) (bool, error) {
 	return c.inner.IsReplicationFactorUpdating(topic)
 }
 
+func (c *LazyClient) CancelPartitionReassignments(topic string, timeout time.Duration) error {
+	err := c.init()
+	if err != nil {
+		return err
+	}
+	return c.inner.CancelPartitionReassignments(topic, timeout)
+}
+
+func (c *LazyClient) ElectPreferredLeaders(topic string, partitions []int32, timeout time.Duration) ([]ElectionError, error) {
+	err := c.init()
+	if err != nil {
+		return nil, err
+	}
+	return c.inner.ElectPreferredLeaders(topic, partitions, timeout)
+}
+
 func (c *LazyClient) CreateACL(s StringlyTypedACL) error {
 	err := c.init()
 	if err != nil {
@@ -138,10 +180,154 @@ func (c *LazyClient) ListACLs() ([]*sarama.ResourceAcls, error) {
 	return c.inner.ListACLs()
 }
 
-func (c *LazyClient) DeleteACL(s StringlyTypedACL) error {
+func (c *LazyClient) DeleteACL(s StringlyTypedACL) ([]StringlyTypedACL, error) {
 	err := c.init()
 	if err != nil {
-		return err
+		return nil, err
 	}
 	return c.inner.DeleteACL(s)
 }
+
+func (c *LazyClient) CreateTopics(topics []Topic, validateOnly bool, timeout time.Duration) (map[string]error, error) {
+	err := c.init()
+	if err != nil {
+		return nil, err
+	}
+	return c.inner.CreateTopics(topics, validateOnly, timeout)
+}
+
+func (c *LazyClient) CanValidateTopics() (bool, error) {
+	err := c.init()
+	if err != nil {
+		return false, err
+	}
+	return c.inner.CanValidateTopics(), nil
+}
+
+func (c *LazyClient) DeleteTopics(names []string, timeout time.Duration) (map[string]error, error) {
+	err := c.init()
+	if err != nil {
+		return nil, err
+	}
+	return c.inner.DeleteTopics(names, timeout)
+}
+
+func (c *LazyClient) UpdateTopics(old map[string]Topic, topics []Topic) (map[string]error, error) {
+	err := c.init()
+	if err != nil {
+		return nil, err
+	}
+	return c.inner.UpdateTopics(old, topics)
+}
+
+func (c *LazyClient) AddPartitionsToTopics(topics []Topic, timeout time.Duration) (map[string]error, error) {
+	err := c.init()
+	if err != nil {
+		return nil, err
+	}
+	return c.inner.AddPartitionsToTopics(topics, timeout)
+}
+
+func (c *LazyClient) ReadTopics(names []string) (map[string]Topic, error) {
+	err := c.init()
+	if err != nil {
+		return nil, err
+	}
+	return c.inner.ReadTopics(names)
+}
+
+func (c *LazyClient) MigrateTopic(t Topic, timeout time.Duration) error {
+	err := c.init()
+	if err != nil {
+		return err
+	}
+	return c.inner.MigrateTopic(t, timeout)
+}
+
+func (c *LazyClient) PartitionWatermarks(topic string) (map[int32]int64, map[int32]int64, error) {
+	err := c.init()
+	if err != nil {
+		return nil, nil, err
+	}
+	return c.inner.PartitionWatermarks(topic)
+}
+
+func (c *LazyClient) OffsetsForTimestamp(topic string, timestamp time.Time) (map[int32]int64, error) {
+	err := c.init()
+	if err != nil {
+		return nil, err
+	}
+	return c.inner.OffsetsForTimestamp(topic, timestamp)
+}
+
+func (c *LazyClient) DeleteRecords(topic string, offsets map[int32]int64, timeout time.Duration) (map[int32]int64, error) {
+	err := c.init()
+	if err != nil {
+		return nil, err
+	}
+	return c.inner.DeleteRecords(topic, offsets, timeout)
+}
+
+func (c *LazyClient) CanReadTopicIDs() (bool, error) {
+	err := c.init()
+	if err != nil {
+		return false, err
+	}
+	return c.inner.CanReadTopicIDs(), nil
+}
+
+func (c *LazyClient) TopicIDs(names []string) (map[string]string, error) {
+	err := c.init()
+	if err != nil {
+		return nil, err
+	}
+	return c.inner.TopicIDs(names)
+}
+
+func (c *LazyClient) ReplaceACL(old, new StringlyTypedACL) error {
+	err := c.init()
+	if err != nil {
+		return err
+	}
+	return c.inner.ReplaceACL(old, new)
+}
+
+func (c *LazyClient) CreateACLs(acls []StringlyTypedACL) (map[string]error, error) {
+	err := c.init()
+	if err != nil {
+		return nil, err
+	}
+	return c.inner.CreateACLs(acls)
+}
+
+func (c *LazyClient) DeleteACLs(acls []StringlyTypedACL) (map[string]error, error) {
+	err := c.init()
+	if err != nil {
+		return nil, err
+	}
+	return c.inner.DeleteACLs(acls)
+}
+
+func (c *LazyClient) DescribePrincipalACLs(principal string) ([]*sarama.ResourceAcls, error) {
+	err := c.init()
+	if err != nil {
+		return nil, err
+	}
+	return c.inner.DescribePrincipalACLs(principal)
+}
+
+func (c *LazyClient) CachedACLs() ([]*sarama.ResourceAcls, error) {
+	err := c.init()
+	if err != nil {
+		return nil, err
+	}
+	return c.inner.CachedACLs()
+}
+
+func (c *LazyClient) FindACLs(s StringlyTypedACL) ([]*sarama.ResourceAcls, error) {
+	err := c.init()
+	if err != nil {
+		return nil, err
+	}
+	return c.inner.FindACLs(s)
+}
@@ -1,9 +1,10 @@
 package kafka
 
 import (
+	"errors"
 	"testing"
 
-	"github.com/Shopify/sarama"
+	"github.com/IBM/sarama"
 )
 
 func Test_LazyClientWithNoConfig(t *testing.T) {
@@ -37,7 +38,7 @@ func Test_LazyClientWithConfigErrors(t *testing.T) {
 	}
 	err := c.init()
 
-	if err != sarama.ErrOutOfBrokers {
+	if !errors.Is(err, sarama.ErrOutOfBrokers) {
 		t.Fatalf("exepted err, got %v", err)
 	}
 }
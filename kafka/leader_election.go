@@ -0,0 +1,113 @@
+package kafka
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/IBM/sarama"
+)
+
+// https://kafka.apache.org/protocol#The_Messages_ElectLeaders
+const apiKeyElectLeaders = 43
+
+// ElectionError describes a partition whose preferred replica could not be
+// elected as leader.
+type ElectionError struct {
+	Partition int32
+	Err       sarama.KError
+	Message   string
+}
+
+func (e ElectionError) Error() string {
+	if e.Message != "" {
+		return fmt.Sprintf("partition %d: %s", e.Partition, e.Message)
+	}
+	return fmt.Sprintf("partition %d: %s", e.Partition, e.Err)
+}
+
+func (c *Client) CanElectLeaders() bool {
+	_, ok := c.supportedAPIs[apiKeyElectLeaders]
+	return ok
+}
+
+// ElectPreferredLeaders runs a preferred leader election for the given
+// partitions of the topic, or for all of them if partitions is empty, waiting
+// up to timeout for the brokers to complete it. It returns the partitions
+// whose preferred leader could not be elected; partitions already led by
+// their preferred replica aren't reported.
+func (c *Client) ElectPreferredLeaders(topic string, partitions []int32, timeout time.Duration) ([]ElectionError, error) {
+	if !c.CanElectLeaders() {
+		return nil, errors.New("Need kafka >= 2.2.0 to elect preferred leaders")
+	}
+
+	if len(partitions) == 0 {
+		if err := c.client.RefreshMetadata(topic); err != nil {
+			return nil, err
+		}
+
+		var err error
+		partitions, err = c.client.Partitions(topic)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	controller, err := c.client.Controller()
+	if err != nil {
+		return nil, err
+	}
+
+	// give the broker a chance to answer before we give up on it
+	conn, err := c.dialBroker(controller.Addr(), func(config *sarama.Config) {
+		config.Net.ReadTimeout = timeout + c.kafkaConfig.Net.ReadTimeout
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := conn.Close(); err != nil {
+			log.Printf("[WARN] Error closing connection to %s: %s", controller.Addr(), err)
+		}
+	}()
+
+	request := &sarama.ElectLeadersRequest{
+		Version:         int16(c.versionForKey(apiKeyElectLeaders, 2)),
+		Type:            sarama.PreferredElection,
+		TopicPartitions: map[string][]int32{topic: partitions},
+		TimeoutMs:       int32(timeout / time.Millisecond),
+	}
+
+	log.Printf("[INFO] Electing preferred leaders for %d partitions of %s", len(partitions), topic)
+	res, err := conn.ElectLeaders(request)
+	if err != nil {
+		return nil, err
+	}
+	if res.ErrorCode != sarama.ErrNoError {
+		return nil, res.ErrorCode
+	}
+
+	return electionErrors(res.ReplicaElectionResults[topic]), nil
+}
+
+// electionErrors returns the partitions whose preferred leader wasn't elected,
+// leaving out the ones already led by it
+func electionErrors(results map[int32]*sarama.PartitionResult) []ElectionError {
+	failed := []ElectionError{}
+	for p, r := range results {
+		if r.ErrorCode == sarama.ErrNoError || r.ErrorCode == sarama.ErrElectionNotNeeded {
+			continue
+		}
+
+		e := ElectionError{Partition: p, Err: r.ErrorCode}
+		if r.ErrorMessage != nil {
+			e.Message = *r.ErrorMessage
+		}
+		failed = append(failed, e)
+	}
+
+	sort.Slice(failed, func(i, j int) bool { return failed[i].Partition < failed[j].Partition })
+	return failed
+}
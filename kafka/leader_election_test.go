@@ -0,0 +1,26 @@
+package kafka
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/IBM/sarama"
+)
+
+func Test_ElectionErrors(t *testing.T) {
+	msg := "broker 3 is offline"
+	results := map[int32]*sarama.PartitionResult{
+		0: {ErrorCode: sarama.ErrNoError},
+		1: {ErrorCode: sarama.ErrElectionNotNeeded},
+		2: {ErrorCode: sarama.ErrPreferredLeaderNotAvailable, ErrorMessage: &msg},
+		3: {ErrorCode: sarama.ErrNotController},
+	}
+
+	expected := []ElectionError{
+		{Partition: 2, Err: sarama.ErrPreferredLeaderNotAvailable, Message: "broker 3 is offline"},
+		{Partition: 3, Err: sarama.ErrNotController},
+	}
+	if got := electionErrors(results); !reflect.DeepEqual(got, expected) {
+		t.Errorf("%v != %v", got, expected)
+	}
+}
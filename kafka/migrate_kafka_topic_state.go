@@ -0,0 +1,43 @@
+package kafka
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+// topicStateDefaults are the defaults of the topic arguments added after the
+// first version of the state, which older states don't have
+var topicStateDefaults = map[string]string{
+	"partition_shrink_strategy": "recreate",
+	"on_destroy":                "delete",
+}
+
+func migrateKafkaTopicState(v int, is *terraform.InstanceState, meta interface{}) (*terraform.InstanceState, error) {
+	switch v {
+	case 0:
+		log.Println("[INFO] Found Kafka topic v0 state; migrating to v1")
+		return migrateKafkaTopicV0toV1(is)
+	default:
+		return is, fmt.Errorf("Unexpected schema version: %d", v)
+	}
+}
+
+func migrateKafkaTopicV0toV1(is *terraform.InstanceState) (*terraform.InstanceState, error) {
+	if is.Empty() {
+		log.Println("[DEBUG] Empty InstanceState; nothing to migrate.")
+		return is, nil
+	}
+	log.Printf("[DEBUG] Topic Attributes before migration: %#v", is.Attributes)
+
+	for k, v := range topicStateDefaults {
+		if _, ok := is.Attributes[k]; !ok {
+			is.Attributes[k] = v
+		}
+	}
+
+	log.Printf("[DEBUG] Topic Attributes after migration: %#v", is.Attributes)
+
+	return is, nil
+}
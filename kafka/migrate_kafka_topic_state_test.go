@@ -0,0 +1,57 @@
+package kafka
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func TestMigrateKafkaTopicV0toV1(t *testing.T) {
+	oldAttributes := map[string]string{
+		"name":               "syslog",
+		"partitions":         "3",
+		"replication_factor": "2",
+	}
+
+	newState, err := migrateKafkaTopicV0toV1(&terraform.InstanceState{
+		ID:         "syslog",
+		Attributes: oldAttributes,
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedAttributes := map[string]string{
+		"name":                      "syslog",
+		"partitions":                "3",
+		"replication_factor":        "2",
+		"partition_shrink_strategy": "recreate",
+		"on_destroy":                "delete",
+	}
+	if !reflect.DeepEqual(newState.Attributes, expectedAttributes) {
+		t.Fatalf("Expected attributes:\n%#v\n\nGiven:\n%#v\n",
+			expectedAttributes, newState.Attributes)
+	}
+}
+
+func TestMigrateKafkaTopicV0toV1_keeps_values(t *testing.T) {
+	oldAttributes := map[string]string{
+		"name":       "syslog",
+		"on_destroy": "abandon",
+	}
+
+	newState, err := migrateKafkaTopicV0toV1(&terraform.InstanceState{
+		ID:         "syslog",
+		Attributes: oldAttributes,
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := newState.Attributes["on_destroy"]; got != "abandon" {
+		t.Fatalf("Expected on_destroy to be kept, got %s", got)
+	}
+}
@@ -0,0 +1,151 @@
+package kafka
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// The roles of kafka_principal_access, and the operations each one needs on
+// each type of resource
+const (
+	roleProducer              = "producer"
+	roleConsumer              = "consumer"
+	roleTransactionalProducer = "transactional_producer"
+	roleStreamsApp            = "streams_app"
+)
+
+var principalAccessRoles = []string{roleProducer, roleConsumer, roleTransactionalProducer, roleStreamsApp}
+
+var roleOperations = map[string]map[string][]string{
+	roleProducer: {
+		"Topic":   {"Write", "Describe"},
+		"Cluster": {"IdempotentWrite"},
+	},
+	roleConsumer: {
+		"Topic": {"Read", "Describe"},
+		"Group": {"Read"},
+	},
+	roleTransactionalProducer: {
+		"Topic":           {"Write", "Describe"},
+		"Cluster":         {"IdempotentWrite"},
+		"TransactionalID": {"Write", "Describe"},
+	},
+	roleStreamsApp: {
+		// streams apps create their own repartition and changelog topics,
+		// and delete the records of repartition topics once consumed
+		"Topic":           {"Read", "Write", "Describe", "Create", "Delete", "DescribeConfigs"},
+		"Cluster":         {"IdempotentWrite"},
+		"Group":           {"Read", "Describe"},
+		"TransactionalID": {"Write", "Describe"},
+	},
+}
+
+// optionalResourceTypes are the types of resources a role can have no names
+// for: streams apps only use transactional IDs with exactly-once processing
+var optionalResourceTypes = map[string][]string{
+	roleStreamsApp: {"TransactionalID"},
+}
+
+// clusterResourceName is the name of the only cluster resource
+const clusterResourceName = "kafka-cluster"
+
+// PrincipalAccess describes the access a principal needs for a role
+type PrincipalAccess struct {
+	Principal               string
+	Host                    string
+	Role                    string
+	Topics                  []string
+	TopicPrefixes           []string
+	Groups                  []string
+	GroupPrefixes           []string
+	TransactionalIDs        []string
+	TransactionalIDPrefixes []string
+}
+
+// Bindings expands the access into the ACLs it needs, keyed by ID
+func (p PrincipalAccess) Bindings() (map[string]StringlyTypedACL, error) {
+	operations, ok := roleOperations[p.Role]
+	if !ok {
+		return nil, fmt.Errorf("unknown role %s, expected one of %v", p.Role, principalAccessRoles)
+	}
+
+	resources := map[string]map[string][]string{
+		"Topic": {
+			"Literal":  p.Topics,
+			"Prefixed": p.TopicPrefixes,
+		},
+		"Group": {
+			"Literal":  p.Groups,
+			"Prefixed": p.GroupPrefixes,
+		},
+		"TransactionalID": {
+			"Literal":  p.TransactionalIDs,
+			"Prefixed": p.TransactionalIDPrefixes,
+		},
+		"Cluster": {
+			"Literal": {clusterResourceName},
+		},
+	}
+
+	missing := []string{}
+	for resourceType := range operations {
+		names := resources[resourceType]
+		if len(names["Literal"]) == 0 && len(names["Prefixed"]) == 0 && !stringInSlice(resourceType, optionalResourceTypes[p.Role]) {
+			missing = append(missing, resourceType)
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return nil, fmt.Errorf("the %s role needs names or prefixes for: %s", p.Role, strings.Join(missing, ", "))
+	}
+
+	bindings := map[string]StringlyTypedACL{}
+	for resourceType, ops := range operations {
+		for patternType, names := range resources[resourceType] {
+			for _, name := range names {
+				for _, op := range ops {
+					a := StringlyTypedACL{
+						ACL: ACL{
+							Principal:      p.Principal,
+							Host:           p.Host,
+							Operation:      op,
+							PermissionType: "Allow",
+						},
+						Resource: Resource{
+							Type:              resourceType,
+							Name:              name,
+							PatternTypeFilter: patternType,
+						},
+					}
+					bindings[a.String()] = a
+				}
+			}
+		}
+	}
+
+	return bindings, nil
+}
+
+// parseACLID parses the ID of an ACL, as returned by StringlyTypedACL.String
+func parseACLID(id string) (StringlyTypedACL, error) {
+	parts := strings.Split(id, "|")
+	if len(parts) != 7 {
+		return StringlyTypedACL{}, errors.New("expected format is acl_principal|acl_host|acl_operation|acl_permission_type|resource_type|resource_name|resource_pattern_type_filter")
+	}
+
+	return StringlyTypedACL{
+		ACL: ACL{
+			Principal:      parts[0],
+			Host:           parts[1],
+			Operation:      parts[2],
+			PermissionType: parts[3],
+		},
+		Resource: Resource{
+			Type:              parts[4],
+			Name:              parts[5],
+			PatternTypeFilter: parts[6],
+		},
+	}, nil
+}
@@ -0,0 +1,124 @@
+package kafka
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func Test_PrincipalAccessBindings(t *testing.T) {
+	p := PrincipalAccess{
+		Principal:     "User:alice",
+		Host:          "*",
+		Role:          roleConsumer,
+		Topics:        []string{"orders"},
+		GroupPrefixes: []string{"billing-"},
+	}
+
+	bindings, err := p.Bindings()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{
+		"User:alice|*|Describe|Allow|Topic|orders|Literal",
+		"User:alice|*|Read|Allow|Group|billing-|Prefixed",
+		"User:alice|*|Read|Allow|Topic|orders|Literal",
+	}
+	if got := aclIDs(bindings); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Got %v, expected %v", got, expected)
+	}
+
+	// every binding must be accepted by the brokers' ACL creation path
+	for _, a := range bindings {
+		if _, err := tfToAclCreation(a); err != nil {
+			t.Errorf("%s: %s", a, err)
+		}
+	}
+}
+
+func Test_PrincipalAccessBindingsProducer(t *testing.T) {
+	p := PrincipalAccess{
+		Principal:               "User:bob",
+		Host:                    "*",
+		Role:                    roleTransactionalProducer,
+		TopicPrefixes:           []string{"orders."},
+		TransactionalIDPrefixes: []string{"orders-producer"},
+	}
+
+	bindings, err := p.Bindings()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{
+		"User:bob|*|Describe|Allow|Topic|orders.|Prefixed",
+		"User:bob|*|Describe|Allow|TransactionalID|orders-producer|Prefixed",
+		"User:bob|*|IdempotentWrite|Allow|Cluster|kafka-cluster|Literal",
+		"User:bob|*|Write|Allow|Topic|orders.|Prefixed",
+		"User:bob|*|Write|Allow|TransactionalID|orders-producer|Prefixed",
+	}
+	if got := aclIDs(bindings); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Got %v, expected %v", got, expected)
+	}
+}
+
+func Test_PrincipalAccessBindingsMissingNames(t *testing.T) {
+	p := PrincipalAccess{
+		Principal: "User:alice",
+		Host:      "*",
+		Role:      roleStreamsApp,
+		Topics:    []string{"orders"},
+	}
+
+	_, err := p.Bindings()
+	if err == nil || !strings.HasSuffix(err.Error(), "needs names or prefixes for: Group") {
+		t.Errorf("expected an error naming the missing groups, got %v", err)
+	}
+}
+
+func Test_PrincipalAccessBindingsStreamsApp(t *testing.T) {
+	p := PrincipalAccess{
+		Principal:     "User:alice",
+		Host:          "*",
+		Role:          roleStreamsApp,
+		TopicPrefixes: []string{"orders-"},
+		Groups:        []string{"orders"},
+	}
+
+	// without exactly-once processing, a streams app has no transactional IDs
+	bindings, err := p.Bindings()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{
+		"User:alice|*|Create|Allow|Topic|orders-|Prefixed",
+		"User:alice|*|Delete|Allow|Topic|orders-|Prefixed",
+		"User:alice|*|DescribeConfigs|Allow|Topic|orders-|Prefixed",
+		"User:alice|*|Describe|Allow|Group|orders|Literal",
+		"User:alice|*|Describe|Allow|Topic|orders-|Prefixed",
+		"User:alice|*|IdempotentWrite|Allow|Cluster|kafka-cluster|Literal",
+		"User:alice|*|Read|Allow|Group|orders|Literal",
+		"User:alice|*|Read|Allow|Topic|orders-|Prefixed",
+		"User:alice|*|Write|Allow|Topic|orders-|Prefixed",
+	}
+	if got := aclIDs(bindings); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Got %v, expected %v", got, expected)
+	}
+}
+
+func Test_ParseACLID(t *testing.T) {
+	a := testACL("Read")
+	parsed, err := parseACLID(a.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(parsed, a) {
+		t.Errorf("Got %v, expected %v", parsed, a)
+	}
+
+	if _, err := parseACLID("User:alice|*|Read"); err == nil {
+		t.Errorf("expected an error parsing an incomplete ID")
+	}
+}
@@ -1,12 +1,18 @@
 package kafka
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log"
+	"time"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
 
+// defaultTimeout is the default of the provider's timeout, in seconds
+const defaultTimeout = 120
+
 func Provider() *schema.Provider {
 	return &schema.Provider{
 		Schema: map[string]*schema.Schema{
@@ -94,22 +100,94 @@ func Provider() *schema.Provider {
 			"timeout": {
 				Type:        schema.TypeInt,
 				Optional:    true,
-				Default:     120,
-				Description: "Timeout in seconds",
+				Default:     defaultTimeout,
+				Description: "Timeout in seconds, also the default timeout of the operations of kafka_topic and kafka_acl",
+			},
+			"ignore_config_keys": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				Description: "Glob patterns of topic configs managed outside of Terraform, which are ignored on every kafka_topic and kafka_topics.",
+				Elem: &schema.Schema{
+					Type:         schema.TypeString,
+					ValidateFunc: validateConfigKeyPattern,
+				},
+			},
+			"pin_topic_configs": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Keep the configs declared on topics as topic-level overrides, even when they match the value of the brokers.",
+			},
+			"strict_acl_lint": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Fail the plan of kafka_acl resources that are overly broad, shadowed by a Deny ACL or redundant, instead of warning about them.",
+			},
+			"allow_broad_acl_deletion": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Allow deleting an ACL whose Any or Match fields also match other ACLs, which are deleted along with it.",
 			},
 		},
 
 		ConfigureFunc: providerConfigure,
 		ResourcesMap: map[string]*schema.Resource{
-			"kafka_topic": kafkaTopicResource(),
-			"kafka_acl":   kafkaACLResource(),
+			"kafka_topic":                  kafkaTopicResource(),
+			"kafka_topics":                 kafkaTopicsResource(),
+			"kafka_acl":                    kafkaACLResource(),
+			"kafka_acls":                   kafkaACLsResource(),
+			"kafka_exclusive_acls":         kafkaExclusiveACLsResource(),
+			"kafka_leader_election":        kafkaLeaderElectionResource(),
+			"kafka_principal_access":       kafkaPrincipalAccessResource(),
+			"kafka_topic_records_deletion": kafkaTopicRecordsDeletionResource(),
 		},
 		DataSourcesMap: map[string]*schema.Resource{
-			"kafka_topic": kafkaTopicDataSource(),
+			"kafka_acl_check": kafkaACLCheckDataSource(),
+			"kafka_topic":     kafkaTopicDataSource(),
 		},
 	}
 }
 
+// operationTimeouts declares the create, update and delete timeouts of a
+// resource. Their default stands for the provider's timeout, which is only
+// known once the provider is configured, so the timeouts are read with
+// operationTimeout.
+func operationTimeouts() *schema.ResourceTimeout {
+	return &schema.ResourceTimeout{
+		Create: schema.DefaultTimeout(defaultTimeout * time.Second),
+		Update: schema.DefaultTimeout(defaultTimeout * time.Second),
+		Delete: schema.DefaultTimeout(defaultTimeout * time.Second),
+	}
+}
+
+// operationTimeout returns the timeout of an operation of a resource, falling
+// back to the provider's timeout when it isn't configured
+func operationTimeout(d *schema.ResourceData, key string, c *LazyClient) time.Duration {
+	if timeout := d.Timeout(key); timeout != defaultTimeout*time.Second {
+		return timeout
+	}
+	return time.Duration(c.Config.Timeout) * time.Second
+}
+
+// operationContext returns the context of an operation of a resource, whose
+// deadline is its operationTimeout. The SDK bounds ctx with the declared
+// default instead, so that deadline is replaced, while Terraform cancelling
+// ctx still cancels the operation.
+func operationContext(ctx context.Context, d *schema.ResourceData, key string, c *LazyClient) (context.Context, context.CancelFunc) {
+	opCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), operationTimeout(d, key, c))
+	stop := context.AfterFunc(ctx, func() {
+		if errors.Is(ctx.Err(), context.Canceled) {
+			cancel()
+		}
+	})
+	return opCtx, func() {
+		stop()
+		cancel()
+	}
+}
+
 func providerConfigure(d *schema.ResourceData) (interface{}, error) {
 	brokers := dTos("bootstrap_servers", d)
 
@@ -134,6 +212,13 @@ func providerConfigure(d *schema.ResourceData) (interface{}, error) {
 		SASLMechanism:           saslMechanism,
 		TLSEnabled:              d.Get("tls_enabled").(bool),
 		Timeout:                 d.Get("timeout").(int),
+		PinTopicConfigs:         d.Get("pin_topic_configs").(bool),
+		StrictACLLint:           d.Get("strict_acl_lint").(bool),
+		AllowBroadACLDeletion:   d.Get("allow_broad_acl_deletion").(bool),
+	}
+
+	if ignored := dTos("ignore_config_keys", d); ignored != nil {
+		config.IgnoreConfigKeys = *ignored
 	}
 
 	if config.CACert == "" {
@@ -8,6 +8,7 @@ import (
 	"os"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
@@ -22,6 +23,41 @@ func TestProvider(t *testing.T) {
 	}
 }
 
+func Test_OperationTimeout(t *testing.T) {
+	c := &LazyClient{Config: &Config{Timeout: 600}}
+	r := kafkaTopicResource()
+
+	if timeout := operationTimeout(r.Data(nil), schema.TimeoutCreate, c); timeout != 10*time.Minute {
+		t.Errorf("Expected an unconfigured timeout to default to the provider's, got %s", timeout)
+	}
+
+	r.Timeouts = &schema.ResourceTimeout{Create: schema.DefaultTimeout(5 * time.Minute)}
+	if timeout := operationTimeout(r.Data(nil), schema.TimeoutCreate, c); timeout != 5*time.Minute {
+		t.Errorf("Expected the configured timeout, got %s", timeout)
+	}
+}
+
+func Test_OperationContext(t *testing.T) {
+	c := &LazyClient{Config: &Config{Timeout: 600}}
+	d := kafkaTopicResource().Data(nil)
+
+	parent, cancelParent := context.WithTimeout(context.Background(), defaultTimeout*time.Second)
+	ctx, cancel := operationContext(parent, d, schema.TimeoutCreate, c)
+	defer cancel()
+
+	deadline, ok := ctx.Deadline()
+	if !ok || time.Until(deadline) <= defaultTimeout*time.Second {
+		t.Errorf("Expected the deadline to be the provider's timeout, got %s", deadline)
+	}
+
+	cancelParent()
+	select {
+	case <-ctx.Done():
+	case <-time.After(time.Second):
+		t.Error("Expected cancelling the parent context to cancel the operation")
+	}
+}
+
 func testAccPreCheck(t *testing.T) {
 	meta := testProvider.Meta()
 	if meta == nil {
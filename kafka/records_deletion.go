@@ -0,0 +1,170 @@
+package kafka
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/IBM/sarama"
+)
+
+// https://kafka.apache.org/protocol#The_Messages_DeleteRecords
+const apiKeyDeleteRecords = 21
+
+// deleteToHighWatermark is the offset that deletes every record of a partition
+const deleteToHighWatermark = -1
+
+func (c *Client) CanDeleteRecords() bool {
+	_, ok := c.supportedAPIs[apiKeyDeleteRecords]
+	return ok
+}
+
+// PartitionWatermarks returns the low and high watermarks of every partition
+// of the topic
+func (c *Client) PartitionWatermarks(topic string) (map[int32]int64, map[int32]int64, error) {
+	if err := c.client.RefreshMetadata(topic); err != nil {
+		return nil, nil, err
+	}
+
+	partitions, err := c.client.Partitions(topic)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	low := make(map[int32]int64, len(partitions))
+	high := make(map[int32]int64, len(partitions))
+	for _, p := range partitions {
+		if low[p], err = c.client.GetOffset(topic, p, sarama.OffsetOldest); err != nil {
+			return nil, nil, err
+		}
+		if high[p], err = c.client.GetOffset(topic, p, sarama.OffsetNewest); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return low, high, nil
+}
+
+// OffsetsForTimestamp returns, for every partition of the topic, the offset
+// of the first record produced at or after the timestamp, or the high
+// watermark when every record is older
+func (c *Client) OffsetsForTimestamp(topic string, timestamp time.Time) (map[int32]int64, error) {
+	_, high, err := c.PartitionWatermarks(topic)
+	if err != nil {
+		return nil, err
+	}
+
+	millis := timestamp.UnixNano() / int64(time.Millisecond)
+	offsets := make(map[int32]int64, len(high))
+	for p := range high {
+		offset, err := c.client.GetOffset(topic, p, millis)
+		if err != nil {
+			return nil, err
+		}
+		if offset < 0 {
+			offset = high[p]
+		}
+		offsets[p] = offset
+	}
+
+	return offsets, nil
+}
+
+// DeleteRecords deletes the records of each partition before the given
+// offset, sending one request to the leader of each partition. It returns the
+// low watermarks of the partitions once the records are deleted.
+func (c *Client) DeleteRecords(topic string, offsets map[int32]int64, timeout time.Duration) (map[int32]int64, error) {
+	if !c.CanDeleteRecords() {
+		return nil, errors.New("Need kafka >= 0.11.0.0 to delete records")
+	}
+
+	byLeader := map[*sarama.Broker]map[int32]int64{}
+	for p, offset := range offsets {
+		leader, err := c.client.Leader(topic, p)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := byLeader[leader]; !ok {
+			byLeader[leader] = map[int32]int64{}
+		}
+		byLeader[leader][p] = offset
+	}
+
+	lowWatermarks := make(map[int32]int64, len(offsets))
+	failed := []string{}
+	for leader, partitionOffsets := range byLeader {
+		req := &sarama.DeleteRecordsRequest{
+			Topics: map[string]*sarama.DeleteRecordsRequestTopic{
+				topic: {PartitionOffsets: partitionOffsets},
+			},
+			Timeout: timeout,
+		}
+
+		log.Printf("[INFO] Deleting records of %d partitions of %s on broker %d", len(partitionOffsets), topic, leader.ID())
+		res, err := leader.DeleteRecords(req)
+		if err != nil {
+			return lowWatermarks, err
+		}
+
+		t, ok := res.Topics[topic]
+		if !ok {
+			return lowWatermarks, sarama.ErrIncompleteResponse
+		}
+		for p, r := range t.Partitions {
+			if r.Err != sarama.ErrNoError {
+				failed = append(failed, fmt.Sprintf("partition %d: %s", p, r.Err))
+				continue
+			}
+			lowWatermarks[p] = r.LowWatermark
+		}
+	}
+
+	if len(failed) > 0 {
+		sort.Strings(failed)
+		return lowWatermarks, fmt.Errorf("could not delete the records of %s: %v", topic, failed)
+	}
+
+	return lowWatermarks, nil
+}
+
+// deletedRecords returns how many records of each partition were deleted,
+// given the low watermarks before and after the deletion
+func deletedRecords(before, after map[int32]int64) map[int32]int64 {
+	deleted := make(map[int32]int64, len(after))
+	for p, low := range after {
+		n := low - before[p]
+		if n < 0 {
+			n = 0
+		}
+		deleted[p] = n
+	}
+	return deleted
+}
+
+// recordsToDelete returns how many records of each partition deleting the
+// records before the target offsets removes. A target of -1 stands for the
+// high watermark of the partition.
+func recordsToDelete(low, high, targets map[int32]int64) (map[int32]int64, error) {
+	counts := make(map[int32]int64, len(targets))
+	for p, target := range targets {
+		h, ok := high[p]
+		if !ok {
+			return nil, fmt.Errorf("partition %d doesn't exist", p)
+		}
+		if target == deleteToHighWatermark {
+			target = h
+		}
+		if target < 0 || target > h {
+			return nil, fmt.Errorf("offset %d of partition %d is out of range, the high watermark is %d", target, p, h)
+		}
+
+		n := target - low[p]
+		if n < 0 {
+			n = 0
+		}
+		counts[p] = n
+	}
+	return counts, nil
+}
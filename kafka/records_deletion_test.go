@@ -0,0 +1,70 @@
+package kafka
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_RecordsToDelete(t *testing.T) {
+	low := map[int32]int64{0: 10, 1: 0, 2: 50}
+	high := map[int32]int64{0: 100, 1: 20, 2: 60}
+
+	counts, err := recordsToDelete(low, high, map[int32]int64{0: 40, 1: deleteToHighWatermark, 2: 30})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[int32]int64{0: 30, 1: 20, 2: 0}
+	if !reflect.DeepEqual(counts, expected) {
+		t.Errorf("Got %v, expected %v", counts, expected)
+	}
+}
+
+func Test_RecordsToDeleteOutOfRange(t *testing.T) {
+	low := map[int32]int64{0: 0}
+	high := map[int32]int64{0: 10}
+
+	for _, targets := range []map[int32]int64{
+		{0: 11},
+		{0: -2},
+		{1: 5},
+	} {
+		if _, err := recordsToDelete(low, high, targets); err == nil {
+			t.Errorf("expected an error for %v", targets)
+		}
+	}
+}
+
+func Test_DeletedRecords(t *testing.T) {
+	before := map[int32]int64{0: 10, 1: 0, 2: 50}
+	after := map[int32]int64{0: 40, 1: 20, 2: 50}
+
+	deleted := deletedRecords(before, after)
+	expected := map[int32]int64{0: 30, 1: 20, 2: 0}
+	if !reflect.DeepEqual(deleted, expected) {
+		t.Errorf("Got %v, expected %v", deleted, expected)
+	}
+}
+
+func Test_RecordsDeletionID(t *testing.T) {
+	id := recordsDeletionID("events", map[int32]int64{10: 5, 2: deleteToHighWatermark, 0: 1500})
+	if id != "events|0:1500,2:-1,10:5" {
+		t.Errorf("Got %s", id)
+	}
+
+	if recordsDeletionID("events", map[int32]int64{0: 1}) == recordsDeletionID("events", map[int32]int64{0: 2}) {
+		t.Error("Expected deletions of different offsets to have different IDs")
+	}
+}
+
+func Test_ValidatePartitionOffsets(t *testing.T) {
+	_, errs := validatePartitionOffsets(map[string]interface{}{"0": 10, "1": -1}, "partition_offsets")
+	if len(errs) != 0 {
+		t.Errorf("expected no errors, got %v", errs)
+	}
+
+	_, errs = validatePartitionOffsets(map[string]interface{}{"a": 10, "-1": 5, "2": -2}, "partition_offsets")
+	if len(errs) != 3 {
+		t.Errorf("expected 3 errors, got %v", errs)
+	}
+}
@@ -8,6 +8,7 @@ import (
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 )
 
 func kafkaACLResource() *schema.Resource {
@@ -15,49 +16,49 @@ func kafkaACLResource() *schema.Resource {
 	return &schema.Resource{
 		CreateContext: aclCreate,
 		ReadContext:   aclRead,
+		UpdateContext: aclUpdate,
 		DeleteContext: aclDelete,
+		CustomizeDiff: aclCustomDiff,
 		Importer: &schema.ResourceImporter{
 			StateContext: importACL,
 		},
+		Timeouts:      operationTimeouts(),
 		SchemaVersion: 1,
 		MigrateState:  migrateKafkaAclState,
 		Schema: map[string]*schema.Schema{
 			"resource_name": {
 				Type:        schema.TypeString,
 				Required:    true,
-				ForceNew:    true,
 				Description: "The name of the resource",
 			},
 			"resource_type": {
-				Type:     schema.TypeString,
-				Required: true,
-				ForceNew: true,
+				Type:         schema.TypeString,
+				Required:     true,
+				ValidateFunc: validation.StringInSlice(aclResourceTypes, false),
 			},
 			"resource_pattern_type_filter": {
-				Type:     schema.TypeString,
-				Default:  "Literal",
-				Optional: true,
-				ForceNew: true,
+				Type:         schema.TypeString,
+				Default:      "Literal",
+				Optional:     true,
+				ValidateFunc: validation.StringInSlice(aclPatternTypes, false),
 			},
 			"acl_principal": {
 				Type:     schema.TypeString,
 				Required: true,
-				ForceNew: true,
 			},
 			"acl_host": {
 				Type:     schema.TypeString,
 				Required: true,
-				ForceNew: true,
 			},
 			"acl_operation": {
-				Type:     schema.TypeString,
-				Required: true,
-				ForceNew: true,
+				Type:         schema.TypeString,
+				Required:     true,
+				ValidateFunc: validation.StringInSlice(aclOperations, false),
 			},
 			"acl_permission_type": {
-				Type:     schema.TypeString,
-				Required: true,
-				ForceNew: true,
+				Type:         schema.TypeString,
+				Required:     true,
+				ValidateFunc: validation.StringInSlice(aclPermissionTypes, false),
 			},
 		},
 	}
@@ -65,10 +66,14 @@ func kafkaACLResource() *schema.Resource {
 
 func aclCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	c := meta.(*LazyClient)
+	ctx, cancel := operationContext(ctx, d, schema.TimeoutCreate, c)
+	defer cancel()
 	a := aclInfo(d)
 
 	log.Printf("[INFO] Creating ACL %s", a)
-	err := c.CreateACL(a)
+	err := withContext(ctx, func() error {
+		return c.CreateACL(a)
+	})
 
 	if err != nil {
 		log.Println("[ERROR] Failed to create ACL")
@@ -77,18 +82,54 @@ func aclCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) di
 
 	d.SetId(a.String())
 
-	return nil
+	return aclLintDiagnostics(c.lintWarnings.take(a.String()))
+}
+
+// aclUpdate replaces the binding in place, creating the new one before
+// deleting the old one so that clients are never left without access
+func aclUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*LazyClient)
+	ctx, cancel := operationContext(ctx, d, schema.TimeoutUpdate, c)
+	defer cancel()
+	old := previousACLInfo(d)
+	a := aclInfo(d)
+
+	log.Printf("[INFO] Replacing ACL %s with %s", old, a)
+	err := withContext(ctx, func() error {
+		return c.ReplaceACL(old, a)
+	})
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(a.String())
+
+	return aclLintDiagnostics(c.lintWarnings.take(a.String()))
 }
 
 func aclDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	c := meta.(*LazyClient)
+	ctx, cancel := operationContext(ctx, d, schema.TimeoutDelete, c)
+	defer cancel()
 	a := aclInfo(d)
 	log.Printf("[INFO] Deleting ACL %s", a)
 
-	err := c.DeleteACL(a)
+	var deleted []StringlyTypedACL
+	err := withContext(ctx, func() (err error) {
+		deleted, err = c.DeleteACL(a)
+		return err
+	})
 	if err != nil {
 		return diag.FromErr(err)
 	}
+
+	if others := otherBindings(a, aclsByID(deleted)); len(others) > 0 {
+		return diag.Diagnostics{{
+			Severity: diag.Warning,
+			Summary:  fmt.Sprintf("Deleting ACL %s also deleted %d other ACLs", a, len(others)),
+			Detail:   strings.Join(others, ", "),
+		}}
+	}
 	return nil
 }
 
@@ -98,67 +139,81 @@ func aclRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag
 	a := aclInfo(d)
 	log.Printf("[INFO] Reading ACL %s", a)
 
-	currentACLs, err := c.ListACLs()
+	currentACLs, err := c.FindACLs(a)
 	if err != nil {
 		return diag.FromErr(err)
 	}
 
-	aclNotFound := true
+	found, nearMisses := matchACL(a, currentACLs)
+	if found {
+		return nil
+	}
 
-	for _, foundACLs := range currentACLs {
-		// find only ACLs where ResourceName matches
-		if foundACLs.ResourceName != a.Resource.Name {
-			continue
-		}
-		if len(foundACLs.Acls) < 1 {
-			break
-		}
-		log.Printf("[INFO] Found (%d) ACL(s) for Resource %s: %+v.", len(foundACLs.Acls), foundACLs.ResourceName, foundACLs)
-
-		for _, acl := range foundACLs.Acls {
-			aclID := StringlyTypedACL{
-				ACL: ACL{
-					Principal:      acl.Principal,
-					Host:           acl.Host,
-					Operation:      ACLOperationToString(acl.Operation),
-					PermissionType: ACLPermissionTypeToString(acl.PermissionType),
-				},
-				Resource: Resource{
-					Type: ACLResourceToString(foundACLs.ResourceType),
-					Name: foundACLs.ResourceName,
-				},
-			}
-
-			// exact match
-			if a.String() == aclID.String() {
-				return nil
-			}
-
-			// partial match -> update state
-			if a.ACL.Principal == aclID.ACL.Principal &&
-				a.ACL.Operation == aclID.ACL.Operation {
-				aclNotFound = false
-				errSet := errSetter{d: d}
-				errSet.Set("acl_principal", acl.Principal)
-				errSet.Set("acl_host", acl.Host)
-				errSet.Set("acl_operation", ACLOperationToString(acl.Operation))
-				errSet.Set("acl_permission_type", ACLPermissionTypeToString(acl.PermissionType))
-				errSet.Set("resource_pattern_type_filter", resourcePatternToString(foundACLs.ResourcePatternType))
-				if errSet.err != nil {
-					return diag.FromErr(err)
-				}
-			}
+	log.Printf("[INFO] Did not find ACL %s", a)
+	d.SetId("")
+	if len(nearMisses) == 0 {
+		return nil
+	}
+
+	// the near misses may belong to other resources, so they're only
+	// reported, and the next apply creates the declared ACL next to them
+	ids := make([]string, 0, len(nearMisses))
+	for _, m := range nearMisses {
+		ids = append(ids, m.String())
+	}
+	return diag.Diagnostics{{
+		Severity: diag.Warning,
+		Summary:  fmt.Sprintf("ACL %s was changed outside of Terraform", a),
+		Detail:   fmt.Sprintf("Found similar ACLs with a different host, permission type or pattern type: %s. The next apply creates the declared ACL, and doesn't delete these.", strings.Join(ids, ", ")),
+	}}
+}
+
+// aclCustomDiff lints the planned ACL against the ACLs of the cluster. The
+// SDK only lets it fail the plan, which strict_acl_lint does; otherwise the
+// problems are logged, and reported as warnings when the ACL is created or
+// replaced. ACLs already in the state aren't linted again on every refresh.
+func aclCustomDiff(ctx context.Context, diff *schema.ResourceDiff, v interface{}) error {
+	for _, key := range []string{"resource_name", "resource_type", "resource_pattern_type_filter", "acl_principal", "acl_host", "acl_operation", "acl_permission_type"} {
+		if !diff.NewValueKnown(key) {
+			return nil
 		}
 	}
 
-	if aclNotFound {
-		log.Printf("[INFO] Did not find ACL %s", a.String())
-		d.SetId("")
+	c := v.(*LazyClient)
+	a := aclInfo(diff)
+	resources, err := c.CachedACLs()
+	if err != nil {
+		return err
+	}
+
+	existing := aclBindings(resources)
+	// the binding being replaced doesn't count, it's deleted by the update
+	delete(existing, diff.Id())
+
+	warnings := lintACL(a, existing)
+	for _, w := range warnings {
+		log.Printf("[WARN] %s", w)
+	}
+	if len(warnings) > 0 && c.Config != nil && c.Config.StrictACLLint {
+		return fmt.Errorf("ACL %s fails strict_acl_lint: %s", a, strings.Join(warnings, "; "))
 	}
 
+	c.lintWarnings.set(a.String(), warnings)
 	return nil
 }
 
+// aclLintDiagnostics returns a warning for each lint problem
+func aclLintDiagnostics(warnings []string) diag.Diagnostics {
+	var diags diag.Diagnostics
+	for _, w := range warnings {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Warning,
+			Summary:  w,
+		})
+	}
+	return diags
+}
+
 func importACL(ctx context.Context, d *schema.ResourceData, m interface{}) ([]*schema.ResourceData, error) {
 	parts := strings.Split(d.Id(), "|")
 	if len(parts) == 7 {
@@ -193,7 +248,29 @@ func (es *errSetter) Set(key string, value interface{}) {
 	es.err = es.d.Set(key, value)
 }
 
-func aclInfo(d *schema.ResourceData) StringlyTypedACL {
+// previousACLInfo returns the binding as it was before the planned changes
+func previousACLInfo(d *schema.ResourceData) StringlyTypedACL {
+	old := func(key string) string {
+		o, _ := d.GetChange(key)
+		return o.(string)
+	}
+
+	return StringlyTypedACL{
+		ACL: ACL{
+			Principal:      old("acl_principal"),
+			Host:           old("acl_host"),
+			Operation:      old("acl_operation"),
+			PermissionType: old("acl_permission_type"),
+		},
+		Resource: Resource{
+			Type:              old("resource_type"),
+			Name:              old("resource_name"),
+			PatternTypeFilter: old("resource_pattern_type_filter"),
+		},
+	}
+}
+
+func aclInfo(d resourceGetter) StringlyTypedACL {
 	s := StringlyTypedACL{
 		ACL: ACL{
 			Principal:      d.Get("acl_principal").(string),
@@ -6,7 +6,7 @@ import (
 	"log"
 	"testing"
 
-	"github.com/Shopify/sarama"
+	"github.com/IBM/sarama"
 	uuid "github.com/hashicorp/go-uuid"
 	r "github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
@@ -31,7 +31,10 @@ func TestAcc_ACLCreateAndUpdate(t *testing.T) {
 			},
 			{
 				Config: cfg(t, bs, fmt.Sprintf(testResourceACL_updateConfig, aclResourceName)),
-				Check:  testResourceACL_updateCheck,
+				Check: r.ComposeTestCheckFunc(
+					testResourceACL_updateCheck,
+					r.TestCheckResourceAttr("kafka_acl.test", "id", fmt.Sprintf("User:Alice|*|Write|Deny|Topic|%s|Prefixed", aclResourceName)),
+				),
 			},
 			{
 				ResourceName:      "kafka_acl.test",
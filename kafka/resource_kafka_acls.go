@@ -0,0 +1,337 @@
+package kafka
+
+import (
+	"context"
+	"log"
+	"sort"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+func kafkaACLsResource() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: aclsCreate,
+		ReadContext:   aclsRead,
+		UpdateContext: aclsUpdate,
+		DeleteContext: aclsDelete,
+		Timeouts:      operationTimeouts(),
+		Schema: map[string]*schema.Schema{
+			"acl": {
+				Type:        schema.TypeSet,
+				Required:    true,
+				Description: "The ACLs to manage.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"resource_name": {
+							Type:        schema.TypeString,
+							Required:    true,
+							Description: "The name of the resource.",
+						},
+						"resource_type": {
+							Type:         schema.TypeString,
+							Required:     true,
+							Description:  "The type of the resource.",
+							ValidateFunc: validation.StringInSlice(aclResourceTypes, false),
+						},
+						"resource_pattern_type_filter": {
+							Type:         schema.TypeString,
+							Default:      "Literal",
+							Optional:     true,
+							Description:  "How resource_name matches resources.",
+							ValidateFunc: validation.StringInSlice(aclPatternTypes, false),
+						},
+						"acl_principal": {
+							Type:        schema.TypeString,
+							Required:    true,
+							Description: "The principal that is being allowed or denied.",
+						},
+						"acl_host": {
+							Type:        schema.TypeString,
+							Required:    true,
+							Description: "The host from which the principal has access.",
+						},
+						"acl_operation": {
+							Type:         schema.TypeString,
+							Required:     true,
+							Description:  "The operation that is being allowed or denied.",
+							ValidateFunc: validation.StringInSlice(aclOperations, false),
+						},
+						"acl_permission_type": {
+							Type:         schema.TypeString,
+							Required:     true,
+							Description:  "The type of permission.",
+							ValidateFunc: validation.StringInSlice(aclPermissionTypes, false),
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func aclsCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*LazyClient)
+	ctx, cancel := operationContext(ctx, d, schema.TimeoutCreate, c)
+	defer cancel()
+	acls := expandACLs(d.Get("acl").(*schema.Set))
+
+	if diags := createACLs(ctx, c, acls); diags.HasError() {
+		return diags
+	}
+
+	d.SetId(resource.UniqueId())
+	return setACLsState(d, acls)
+}
+
+// createACLs creates the ACLs of a new resource. Terraform taints resources
+// whose creation fails, so when some ACLs fail the ones this call created are
+// deleted again, and the create can be retried without the resource owning
+// ACLs it doesn't track. CreateAcls succeeds for bindings that already exist,
+// so the existing ones are looked up first and never rolled back.
+func createACLs(ctx context.Context, c *LazyClient, acls map[string]StringlyTypedACL) diag.Diagnostics {
+	resources, err := c.ListACLs()
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	preexisting := existingACLs(acls, aclBindings(resources))
+
+	var failed map[string]error
+	err = withContext(ctx, func() (err error) {
+		failed, err = c.CreateACLs(aclList(acls))
+		return err
+	})
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	if len(failed) > 0 {
+		diags := errorDiagnostics("Could not create ACL", failed)
+		return append(diags, rollbackACLs(c, createdACLs(acls, preexisting, failed))...)
+	}
+	return nil
+}
+
+// createdACLs returns the ACLs that a CreateAcls request created: the ones
+// that neither failed nor existed before
+func createdACLs(acls, preexisting map[string]StringlyTypedACL, failed map[string]error) map[string]StringlyTypedACL {
+	created := map[string]StringlyTypedACL{}
+	for id, a := range withoutFailedACLs(acls, failed) {
+		if _, ok := preexisting[id]; !ok {
+			created[id] = a
+		}
+	}
+	return created
+}
+
+func rollbackACLs(c *LazyClient, created map[string]StringlyTypedACL) diag.Diagnostics {
+	if len(created) == 0 {
+		return nil
+	}
+
+	log.Printf("[WARN] Deleting the %d ACLs created before the failure", len(created))
+	failed, err := c.DeleteACLs(aclList(created))
+	if err != nil {
+		return diag.Errorf("could not delete the ACLs created before the failure: %s", err)
+	}
+
+	return errorDiagnostics("Could not delete ACL created before the failure", failed)
+}
+
+func aclsRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*LazyClient)
+	declared := expandACLs(d.Get("acl").(*schema.Set))
+
+	resources, err := c.CachedACLs()
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	// ACLs deleted outside of Terraform are left out, so they're created again
+	return setACLsState(d, existingACLs(declared, aclBindings(resources)))
+}
+
+// aclsUpdate creates the added ACLs before deleting the removed ones, so that
+// clients don't lose access to resources whose ACLs are being changed
+func aclsUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*LazyClient)
+	ctx, cancel := operationContext(ctx, d, schema.TimeoutUpdate, c)
+	defer cancel()
+	o, n := d.GetChange("acl")
+	old := expandACLs(o.(*schema.Set))
+	acls := expandACLs(n.(*schema.Set))
+	added, removed := aclChanges(old, acls)
+
+	var diags diag.Diagnostics
+	// the ACLs in the state once the update is done
+	state := map[string]StringlyTypedACL{}
+	for id, a := range old {
+		state[id] = a
+	}
+
+	if len(added) > 0 {
+		var failed map[string]error
+		err := withContext(ctx, func() (err error) {
+			failed, err = c.CreateACLs(aclList(added))
+			return err
+		})
+		if err != nil {
+			return diag.FromErr(err)
+		}
+
+		diags = append(diags, errorDiagnostics("Could not create ACL", failed)...)
+		for id, a := range withoutFailedACLs(added, failed) {
+			state[id] = a
+		}
+	}
+
+	if len(removed) > 0 {
+		var failed map[string]error
+		err := withContext(ctx, func() (err error) {
+			failed, err = c.DeleteACLs(aclList(removed))
+			return err
+		})
+		if err != nil {
+			return append(diags, append(diag.FromErr(err), setACLsState(d, state)...)...)
+		}
+
+		// ACLs that couldn't be deleted stay in the state, so the next
+		// apply deletes them again
+		diags = append(diags, errorDiagnostics("Could not delete ACL", failed)...)
+		for id := range withoutFailedACLs(removed, failed) {
+			delete(state, id)
+		}
+	}
+
+	return append(diags, setACLsState(d, state)...)
+}
+
+func aclsDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*LazyClient)
+	ctx, cancel := operationContext(ctx, d, schema.TimeoutDelete, c)
+	defer cancel()
+	acls := expandACLs(d.Get("acl").(*schema.Set))
+
+	var failed map[string]error
+	err := withContext(ctx, func() (err error) {
+		failed, err = c.DeleteACLs(aclList(acls))
+		return err
+	})
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	if len(failed) > 0 {
+		return errorDiagnostics("Could not delete ACL", failed)
+	}
+
+	d.SetId("")
+	return nil
+}
+
+// aclChanges returns the ACLs that are in new but not in old, and the ones
+// that are in old but not in new
+func aclChanges(old, new map[string]StringlyTypedACL) (added, removed map[string]StringlyTypedACL) {
+	added = map[string]StringlyTypedACL{}
+	removed = map[string]StringlyTypedACL{}
+	for id, a := range new {
+		if _, ok := old[id]; !ok {
+			added[id] = a
+		}
+	}
+	for id, a := range old {
+		if _, ok := new[id]; !ok {
+			removed[id] = a
+		}
+	}
+	return added, removed
+}
+
+// existingACLs returns the declared ACLs that exist in the cluster
+func existingACLs(declared, existing map[string]StringlyTypedACL) map[string]StringlyTypedACL {
+	found := map[string]StringlyTypedACL{}
+	for id, a := range declared {
+		if _, ok := existing[id]; ok {
+			found[id] = a
+		} else {
+			log.Printf("[INFO] Did not find ACL %s", id)
+		}
+	}
+	return found
+}
+
+func withoutFailedACLs(acls map[string]StringlyTypedACL, failed map[string]error) map[string]StringlyTypedACL {
+	succeeded := map[string]StringlyTypedACL{}
+	for id, a := range acls {
+		if _, ok := failed[id]; !ok {
+			succeeded[id] = a
+		}
+	}
+	return succeeded
+}
+
+func expandACLs(set *schema.Set) map[string]StringlyTypedACL {
+	acls := map[string]StringlyTypedACL{}
+	for _, v := range set.List() {
+		m := v.(map[string]interface{})
+		a := StringlyTypedACL{
+			ACL: ACL{
+				Principal:      m["acl_principal"].(string),
+				Host:           m["acl_host"].(string),
+				Operation:      m["acl_operation"].(string),
+				PermissionType: m["acl_permission_type"].(string),
+			},
+			Resource: Resource{
+				Type:              m["resource_type"].(string),
+				Name:              m["resource_name"].(string),
+				PatternTypeFilter: m["resource_pattern_type_filter"].(string),
+			},
+		}
+		acls[a.String()] = a
+	}
+	return acls
+}
+
+func flattenACLs(acls map[string]StringlyTypedACL) []interface{} {
+	flattened := make([]interface{}, 0, len(acls))
+	for _, id := range aclIDs(acls) {
+		a := acls[id]
+		flattened = append(flattened, map[string]interface{}{
+			"resource_name":                a.Resource.Name,
+			"resource_type":                a.Resource.Type,
+			"resource_pattern_type_filter": a.Resource.PatternTypeFilter,
+			"acl_principal":                a.ACL.Principal,
+			"acl_host":                     a.ACL.Host,
+			"acl_operation":                a.ACL.Operation,
+			"acl_permission_type":          a.ACL.PermissionType,
+		})
+	}
+	return flattened
+}
+
+func setACLsState(d *schema.ResourceData, acls map[string]StringlyTypedACL) diag.Diagnostics {
+	errSet := errSetter{d: d}
+	errSet.Set("acl", flattenACLs(acls))
+	if errSet.err != nil {
+		return diag.FromErr(errSet.err)
+	}
+	return nil
+}
+
+func aclList(acls map[string]StringlyTypedACL) []StringlyTypedACL {
+	list := make([]StringlyTypedACL, 0, len(acls))
+	for _, id := range aclIDs(acls) {
+		list = append(list, acls[id])
+	}
+	return list
+}
+
+func aclIDs(acls map[string]StringlyTypedACL) []string {
+	ids := make([]string, 0, len(acls))
+	for id := range acls {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
@@ -0,0 +1,196 @@
+package kafka
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/IBM/sarama"
+	uuid "github.com/hashicorp/go-uuid"
+	r "github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func TestAcc_BulkACLs(t *testing.T) {
+	u, err := uuid.GenerateUUID()
+	if err != nil {
+		t.Fatal(err)
+	}
+	name := fmt.Sprintf("bulk-acls-%s", u)
+	bs := testBootstrapServers[0]
+
+	r.Test(t, r.TestCase{
+		ProviderFactories: overrideProviderFactory(),
+		PreCheck:          func() { testAccPreCheck(t) },
+		CheckDestroy:      func(s *terraform.State) error { return testAccCheckAclDestroy(name) },
+		Steps: []r.TestStep{
+			{
+				Config: cfg(t, bs, fmt.Sprintf(testResourceACLs_initial, name, name)),
+				Check: r.ComposeTestCheckFunc(
+					r.TestCheckResourceAttr("kafka_acls.test", "acl.#", "2"),
+					testResourceACLs_check(name, []string{"Read", "Write"}),
+				),
+			},
+			{
+				Config: cfg(t, bs, fmt.Sprintf(testResourceACLs_updated, name, name)),
+				Check: r.ComposeTestCheckFunc(
+					r.TestCheckResourceAttr("kafka_acls.test", "acl.#", "2"),
+					testResourceACLs_check(name, []string{"Describe", "Read"}),
+				),
+			},
+		},
+	})
+}
+
+func testResourceACLs_check(name string, operations []string) r.TestCheckFunc {
+	return func(s *terraform.State) error {
+		client := testProvider.Meta().(*LazyClient)
+		acls, err := client.ListACLs()
+		if err != nil {
+			return err
+		}
+
+		found := []string{}
+		for _, a := range aclBindings(acls) {
+			if a.Resource.Name == name {
+				found = append(found, a.ACL.Operation)
+			}
+		}
+		sort.Strings(found)
+
+		if !reflect.DeepEqual(found, operations) {
+			return fmt.Errorf("expected the ACLs of %s to be for %v, got %v", name, operations, found)
+		}
+		return nil
+	}
+}
+
+func Test_ACLChanges(t *testing.T) {
+	read := testACL("Read")
+	write := testACL("Write")
+	describe := testACL("Describe")
+
+	old := map[string]StringlyTypedACL{read.String(): read, write.String(): write}
+	new := map[string]StringlyTypedACL{read.String(): read, describe.String(): describe}
+
+	added, removed := aclChanges(old, new)
+	if !reflect.DeepEqual(aclIDs(added), []string{describe.String()}) {
+		t.Errorf("expected %s to be added, got %v", describe, aclIDs(added))
+	}
+	if !reflect.DeepEqual(aclIDs(removed), []string{write.String()}) {
+		t.Errorf("expected %s to be removed, got %v", write, aclIDs(removed))
+	}
+}
+
+func Test_ACLBindings(t *testing.T) {
+	resources := []*sarama.ResourceAcls{
+		{
+			Resource: sarama.Resource{
+				ResourceType:        sarama.AclResourceTopic,
+				ResourceName:        "syslog",
+				ResourcePatternType: sarama.AclPatternLiteral,
+			},
+			Acls: []*sarama.Acl{
+				{Principal: "User:Alice", Host: "*", Operation: sarama.AclOperationRead, PermissionType: sarama.AclPermissionAllow},
+				{Principal: "User:Alice", Host: "*", Operation: sarama.AclOperationWrite, PermissionType: sarama.AclPermissionAllow},
+			},
+		},
+	}
+
+	bindings := aclBindings(resources)
+	expected := []string{testACL("Read").String(), testACL("Write").String()}
+	if !reflect.DeepEqual(aclIDs(bindings), expected) {
+		t.Errorf("Got %v, expected %v", aclIDs(bindings), expected)
+	}
+}
+
+func Test_ExistingACLs(t *testing.T) {
+	read := testACL("Read")
+	write := testACL("Write")
+
+	declared := map[string]StringlyTypedACL{read.String(): read, write.String(): write}
+	existing := map[string]StringlyTypedACL{read.String(): read}
+
+	found := existingACLs(declared, existing)
+	if !reflect.DeepEqual(aclIDs(found), []string{read.String()}) {
+		t.Errorf("expected only %s to exist, got %v", read, aclIDs(found))
+	}
+}
+
+func Test_CreatedACLs(t *testing.T) {
+	read := testACL("Read")
+	write := testACL("Write")
+	describe := testACL("Describe")
+
+	acls := map[string]StringlyTypedACL{read.String(): read, write.String(): write, describe.String(): describe}
+	preexisting := map[string]StringlyTypedACL{read.String(): read}
+	failed := map[string]error{describe.String(): errors.New("invalid")}
+
+	// read existed before the create, so rolling it back would delete it
+	created := createdACLs(acls, preexisting, failed)
+	if !reflect.DeepEqual(aclIDs(created), []string{write.String()}) {
+		t.Errorf("expected only %s to be created, got %v", write, aclIDs(created))
+	}
+}
+
+func testACL(operation string) StringlyTypedACL {
+	return StringlyTypedACL{
+		ACL: ACL{
+			Principal:      "User:Alice",
+			Host:           "*",
+			Operation:      operation,
+			PermissionType: "Allow",
+		},
+		Resource: Resource{
+			Type:              "Topic",
+			Name:              "syslog",
+			PatternTypeFilter: "Literal",
+		},
+	}
+}
+
+const testResourceACLs_initial = `
+resource "kafka_acls" "test" {
+  acl {
+    resource_name       = "%s"
+    resource_type       = "Topic"
+    acl_principal       = "User:Alice"
+    acl_host            = "*"
+    acl_operation       = "Read"
+    acl_permission_type = "Allow"
+  }
+
+  acl {
+    resource_name       = "%s"
+    resource_type       = "Topic"
+    acl_principal       = "User:Alice"
+    acl_host            = "*"
+    acl_operation       = "Write"
+    acl_permission_type = "Allow"
+  }
+}
+`
+
+const testResourceACLs_updated = `
+resource "kafka_acls" "test" {
+  acl {
+    resource_name       = "%s"
+    resource_type       = "Topic"
+    acl_principal       = "User:Alice"
+    acl_host            = "*"
+    acl_operation       = "Read"
+    acl_permission_type = "Allow"
+  }
+
+  acl {
+    resource_name       = "%s"
+    resource_type       = "Topic"
+    acl_principal       = "User:Alice"
+    acl_host            = "*"
+    acl_operation       = "Describe"
+    acl_permission_type = "Allow"
+  }
+}
+`
@@ -0,0 +1,197 @@
+package kafka
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// kafkaExclusiveACLsResource makes Terraform the source of truth for the ACLs
+// of a principal: its ACLs that aren't managed by Terraform show up as drift,
+// and are deleted on apply.
+func kafkaExclusiveACLsResource() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: exclusiveACLsCreate,
+		ReadContext:   exclusiveACLsRead,
+		UpdateContext: exclusiveACLsUpdate,
+		DeleteContext: exclusiveACLsDelete,
+		CustomizeDiff: exclusiveACLsCustomDiff,
+		Timeouts: &schema.ResourceTimeout{
+			Update: schema.DefaultTimeout(defaultTimeout * time.Second),
+		},
+		Schema: map[string]*schema.Schema{
+			"principal": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "The principal whose ACLs are managed exclusively, e.g. User:alice.",
+			},
+			"resource_name_prefix": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				ForceNew:    true,
+				Description: "Only manage the ACLs of resources whose name starts with this prefix.",
+			},
+			"managed_acls": {
+				Type:        schema.TypeSet,
+				Required:    true,
+				Description: "The IDs of the principal's ACLs that are managed by Terraform, e.g. from kafka_acl or kafka_principal_access.",
+				Elem: &schema.Schema{
+					Type:         schema.TypeString,
+					ValidateFunc: validateACLID,
+				},
+			},
+			"unmanaged_acls": {
+				Type:        schema.TypeSet,
+				Computed:    true,
+				Description: "The IDs of the principal's ACLs that aren't managed by Terraform, which the next apply deletes.",
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+		},
+	}
+}
+
+func exclusiveACLsCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	principal := d.Get("principal").(string)
+	id := principal
+	if prefix := d.Get("resource_name_prefix").(string); prefix != "" {
+		id = strings.Join([]string{principal, prefix}, "|")
+	}
+	d.SetId(id)
+
+	diags := exclusiveACLsRead(ctx, d, meta)
+	if diags.HasError() {
+		return diags
+	}
+
+	// nothing is deleted before it has shown up in a plan
+	if unmanaged := d.Get("unmanaged_acls").(*schema.Set); unmanaged.Len() > 0 {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Warning,
+			Summary:  fmt.Sprintf("%s has %d ACLs that aren't managed by Terraform", principal, unmanaged.Len()),
+			Detail:   fmt.Sprintf("The next apply deletes them: %s", strings.Join(setToStrings(unmanaged), ", ")),
+		})
+	}
+
+	return diags
+}
+
+func exclusiveACLsRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*LazyClient)
+	principal := d.Get("principal").(string)
+
+	resources, err := c.DescribePrincipalACLs(principal)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	managed, err := parseACLIDs(d.Get("managed_acls").(*schema.Set))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	unmanaged := unmanagedACLs(aclBindings(resources), managed, d.Get("resource_name_prefix").(string))
+	log.Printf("[DEBUG] %s has %d unmanaged ACLs", principal, len(unmanaged))
+
+	return setUnmanagedACLs(d, unmanaged)
+}
+
+// exclusiveACLsUpdate deletes the unmanaged ACLs the plan showed, unless they
+// became managed since
+func exclusiveACLsUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*LazyClient)
+	ctx, cancel := operationContext(ctx, d, schema.TimeoutUpdate, c)
+	defer cancel()
+	o, _ := d.GetChange("unmanaged_acls")
+	planned, err := parseACLIDs(o.(*schema.Set))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	managed, err := parseACLIDs(d.Get("managed_acls").(*schema.Set))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	removed, _ := aclChanges(managed, planned)
+	if len(removed) == 0 {
+		return setUnmanagedACLs(d, removed)
+	}
+
+	log.Printf("[INFO] Deleting %d ACLs of %s that aren't managed by Terraform", len(removed), d.Get("principal").(string))
+	var failed map[string]error
+	err = withContext(ctx, func() (err error) {
+		failed, err = c.DeleteACLs(aclList(removed))
+		return err
+	})
+	if err != nil {
+		return append(diag.FromErr(err), setUnmanagedACLs(d, removed)...)
+	}
+
+	// the ACLs that couldn't be deleted are still unmanaged
+	remaining := map[string]StringlyTypedACL{}
+	for id := range failed {
+		remaining[id] = removed[id]
+	}
+
+	return append(errorDiagnostics("Could not delete ACL", failed), setUnmanagedACLs(d, remaining)...)
+}
+
+func exclusiveACLsDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	// the ACLs of the principal belong to the resources managing them, so
+	// this only removes it from the state
+	d.SetId("")
+	return nil
+}
+
+// exclusiveACLsCustomDiff plans the deletion of the unmanaged ACLs
+func exclusiveACLsCustomDiff(ctx context.Context, diff *schema.ResourceDiff, v interface{}) error {
+	if diff.Id() == "" {
+		return nil
+	}
+
+	if diff.Get("unmanaged_acls").(*schema.Set).Len() > 0 {
+		return diff.SetNew("unmanaged_acls", []string{})
+	}
+	return nil
+}
+
+func validateACLID(i interface{}, k string) (warnings []string, errs []error) {
+	id, ok := i.(string)
+	if !ok {
+		return nil, []error{fmt.Errorf("expected type of %s to be string", k)}
+	}
+	if _, err := parseACLID(id); err != nil {
+		return nil, []error{fmt.Errorf("%s: '%s' is not an ACL ID, %s", k, id, err)}
+	}
+	return nil, nil
+}
+
+// unmanagedACLs returns the ACLs that aren't managed, among the ones of
+// resources whose name starts with prefix
+func unmanagedACLs(existing, managed map[string]StringlyTypedACL, prefix string) map[string]StringlyTypedACL {
+	unmanaged := map[string]StringlyTypedACL{}
+	for id, a := range existing {
+		if !strings.HasPrefix(a.Resource.Name, prefix) {
+			continue
+		}
+		if _, ok := managed[id]; !ok {
+			unmanaged[id] = a
+		}
+	}
+	return unmanaged
+}
+
+func setUnmanagedACLs(d *schema.ResourceData, acls map[string]StringlyTypedACL) diag.Diagnostics {
+	errSet := errSetter{d: d}
+	errSet.Set("unmanaged_acls", aclIDs(acls))
+	if errSet.err != nil {
+		return diag.FromErr(errSet.err)
+	}
+	return nil
+}
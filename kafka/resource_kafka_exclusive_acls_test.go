@@ -0,0 +1,112 @@
+package kafka
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	uuid "github.com/hashicorp/go-uuid"
+	r "github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func TestAcc_ExclusiveACLs(t *testing.T) {
+	u, err := uuid.GenerateUUID()
+	if err != nil {
+		t.Fatal(err)
+	}
+	name := fmt.Sprintf("exclusive-%s", u)
+	bs := testBootstrapServers[0]
+
+	unmanaged := StringlyTypedACL{
+		ACL: ACL{
+			Principal:      "User:Exclusive",
+			Host:           "*",
+			Operation:      "Write",
+			PermissionType: "Allow",
+		},
+		Resource: Resource{
+			Type:              "Topic",
+			Name:              name,
+			PatternTypeFilter: "Literal",
+		},
+	}
+
+	r.Test(t, r.TestCase{
+		ProviderFactories: overrideProviderFactory(),
+		PreCheck:          func() { testAccPreCheck(t) },
+		CheckDestroy:      func(s *terraform.State) error { return testAccCheckAclDestroy(name) },
+		Steps: []r.TestStep{
+			{
+				PreConfig: func() {
+					client := testProvider.Meta().(*LazyClient)
+					if err := client.CreateACL(unmanaged); err != nil {
+						t.Fatal(err)
+					}
+				},
+				Config: cfg(t, bs, fmt.Sprintf(testResourceExclusiveACLs_config, name, name)),
+				Check: r.ComposeTestCheckFunc(
+					r.TestCheckResourceAttr("kafka_exclusive_acls.test", "unmanaged_acls.#", "1"),
+					r.TestCheckTypeSetElemAttr("kafka_exclusive_acls.test", "unmanaged_acls.*", unmanaged.String()),
+				),
+				// the unmanaged ACL is only deleted once it was planned
+				ExpectNonEmptyPlan: true,
+			},
+			{
+				Config: cfg(t, bs, fmt.Sprintf(testResourceExclusiveACLs_config, name, name)),
+				Check: r.ComposeTestCheckFunc(
+					r.TestCheckResourceAttr("kafka_exclusive_acls.test", "unmanaged_acls.#", "0"),
+					testResourceACLs_check(name, []string{"Read"}),
+				),
+			},
+		},
+	})
+}
+
+func Test_UnmanagedACLs(t *testing.T) {
+	read := testACL("Read")
+	write := testACL("Write")
+	other := testACL("Write")
+	other.Resource.Name = "audit"
+
+	existing := map[string]StringlyTypedACL{read.String(): read, write.String(): write, other.String(): other}
+	managed := map[string]StringlyTypedACL{read.String(): read}
+
+	got := aclIDs(unmanagedACLs(existing, managed, ""))
+	expected := []string{other.String(), write.String()}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Got %v, expected %v", got, expected)
+	}
+
+	got = aclIDs(unmanagedACLs(existing, managed, "sys"))
+	expected = []string{write.String()}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Got %v, expected only the ACLs of resources starting with sys: %v", got, expected)
+	}
+}
+
+func Test_ValidateACLID(t *testing.T) {
+	if _, errs := validateACLID(testACL("Read").String(), "managed_acls"); len(errs) != 0 {
+		t.Errorf("expected no errors, got %v", errs)
+	}
+	if _, errs := validateACLID("User:Alice|*|Read", "managed_acls"); len(errs) != 1 {
+		t.Errorf("expected an error, got %v", errs)
+	}
+}
+
+const testResourceExclusiveACLs_config = `
+resource "kafka_acl" "test" {
+  resource_name       = "%s"
+  resource_type       = "Topic"
+  acl_principal       = "User:Exclusive"
+  acl_host            = "*"
+  acl_operation       = "Read"
+  acl_permission_type = "Allow"
+}
+
+resource "kafka_exclusive_acls" "test" {
+  principal            = "User:Exclusive"
+  resource_name_prefix = "%s"
+  managed_acls         = [kafka_acl.test.id]
+}
+`
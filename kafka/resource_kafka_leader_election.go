@@ -0,0 +1,110 @@
+package kafka
+
+import (
+	"context"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+func kafkaLeaderElectionResource() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: leaderElectionCreate,
+		ReadContext:   leaderElectionRead,
+		DeleteContext: leaderElectionDelete,
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(defaultTimeout * time.Second),
+		},
+		Schema: map[string]*schema.Schema{
+			"topic": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "The name of the topic whose leaders should be elected.",
+			},
+			"partitions": {
+				Type:        schema.TypeSet,
+				Optional:    true,
+				ForceNew:    true,
+				Description: "The partitions to elect leaders for. Defaults to all partitions of the topic.",
+				Elem: &schema.Schema{
+					Type:         schema.TypeInt,
+					ValidateFunc: validation.IntAtLeast(0),
+				},
+			},
+			"triggers": {
+				Type:        schema.TypeMap,
+				Optional:    true,
+				ForceNew:    true,
+				Description: "A map of arbitrary strings that, when changed, will run the election again.",
+				Elem:        schema.TypeString,
+			},
+			"unelected_partitions": {
+				Type:        schema.TypeMap,
+				Computed:    true,
+				Description: "The partitions whose preferred leader could not be elected, with the reason.",
+				Elem:        schema.TypeString,
+			},
+		},
+	}
+}
+
+func leaderElectionCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*LazyClient)
+	ctx, cancel := operationContext(ctx, d, schema.TimeoutCreate, c)
+	defer cancel()
+	topic := d.Get("topic").(string)
+
+	partitions := []int32{}
+	for _, p := range d.Get("partitions").(*schema.Set).List() {
+		partitions = append(partitions, int32(p.(int)))
+	}
+
+	timeout := remainingTimeout(ctx, d.Timeout(schema.TimeoutCreate))
+	failed, err := c.ElectPreferredLeaders(topic, partitions, timeout)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	unelected := map[string]string{}
+	for _, e := range failed {
+		unelected[strconv.Itoa(int(e.Partition))] = e.Error()
+	}
+	diags := electionWarnings(topic, failed)
+
+	d.SetId(topic)
+	errSet := errSetter{d: d}
+	errSet.Set("unelected_partitions", unelected)
+	if errSet.err != nil {
+		return append(diags, diag.FromErr(errSet.err)...)
+	}
+
+	return diags
+}
+
+func leaderElectionRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*LazyClient)
+	topic := d.Get("topic").(string)
+
+	_, err := c.ReadTopic(topic, false)
+	if err != nil {
+		if _, ok := err.(TopicMissingError); ok {
+			log.Printf("[INFO] Topic %s of the leader election is gone", topic)
+			d.SetId("")
+			return nil
+		}
+		return diag.FromErr(err)
+	}
+
+	return nil
+}
+
+func leaderElectionDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	// an election can't be undone, so this only removes it from the state
+	d.SetId("")
+	return nil
+}
@@ -0,0 +1,263 @@
+package kafka
+
+import (
+	"context"
+	"log"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+var principalAccessNames = []string{
+	"topics",
+	"topic_prefixes",
+	"groups",
+	"group_prefixes",
+	"transactional_ids",
+	"transactional_id_prefixes",
+}
+
+func kafkaPrincipalAccessResource() *schema.Resource {
+	s := map[string]*schema.Schema{
+		"principal": {
+			Type:        schema.TypeString,
+			Required:    true,
+			Description: "The principal to grant access to, e.g. User:alice.",
+		},
+		"role": {
+			Type:         schema.TypeString,
+			Required:     true,
+			Description:  "What the principal does: producer, consumer, transactional_producer or streams_app.",
+			ValidateFunc: validation.StringInSlice(principalAccessRoles, false),
+		},
+		"host": {
+			Type:        schema.TypeString,
+			Optional:    true,
+			Default:     "*",
+			Description: "The host from which the principal has access.",
+		},
+		"acls": {
+			Type:        schema.TypeSet,
+			Computed:    true,
+			Description: "The IDs of the ACLs granted to the principal.",
+			Elem:        &schema.Schema{Type: schema.TypeString},
+		},
+	}
+	for _, key := range principalAccessNames {
+		s[key] = &schema.Schema{
+			Type:     schema.TypeSet,
+			Optional: true,
+			Elem:     &schema.Schema{Type: schema.TypeString},
+		}
+	}
+	s["topics"].Description = "The names of the topics the principal accesses."
+	s["topic_prefixes"].Description = "The prefixes of the topics the principal accesses."
+	s["groups"].Description = "The names of the consumer groups the principal uses."
+	s["group_prefixes"].Description = "The prefixes of the consumer groups the principal uses."
+	s["transactional_ids"].Description = "The transactional IDs the principal uses."
+	s["transactional_id_prefixes"].Description = "The prefixes of the transactional IDs the principal uses."
+
+	return &schema.Resource{
+		CreateContext: principalAccessCreate,
+		ReadContext:   principalAccessRead,
+		UpdateContext: principalAccessUpdate,
+		DeleteContext: principalAccessDelete,
+		CustomizeDiff: principalAccessCustomDiff,
+		Timeouts:      operationTimeouts(),
+		Schema:        s,
+	}
+}
+
+func principalAccessCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*LazyClient)
+	ctx, cancel := operationContext(ctx, d, schema.TimeoutCreate, c)
+	defer cancel()
+	acls, err := principalAccessInfo(d).Bindings()
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	if diags := createACLs(ctx, c, acls); diags.HasError() {
+		return diags
+	}
+
+	d.SetId(resource.UniqueId())
+	return setPrincipalAccessACLs(d, acls)
+}
+
+func principalAccessRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*LazyClient)
+	acls, err := principalAccessInfo(d).Bindings()
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	resources, err := c.CachedACLs()
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	// ACLs deleted outside of Terraform are left out, so that the diff
+	// creates them again
+	return setPrincipalAccessACLs(d, existingACLs(acls, aclBindings(resources)))
+}
+
+// principalAccessUpdate creates the ACLs the principal now needs before
+// deleting the ones it doesn't need anymore
+func principalAccessUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*LazyClient)
+	ctx, cancel := operationContext(ctx, d, schema.TimeoutUpdate, c)
+	defer cancel()
+	o, _ := d.GetChange("acls")
+	old, err := parseACLIDs(o.(*schema.Set))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	acls, err := principalAccessInfo(d).Bindings()
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	added, removed := aclChanges(old, acls)
+
+	var diags diag.Diagnostics
+	state := map[string]StringlyTypedACL{}
+	for id, a := range old {
+		state[id] = a
+	}
+
+	if len(added) > 0 {
+		log.Printf("[INFO] Granting %d ACLs to %s", len(added), d.Get("principal").(string))
+		var failed map[string]error
+		err := withContext(ctx, func() (err error) {
+			failed, err = c.CreateACLs(aclList(added))
+			return err
+		})
+		if err != nil {
+			return diag.FromErr(err)
+		}
+
+		diags = append(diags, errorDiagnostics("Could not create ACL", failed)...)
+		for id, a := range withoutFailedACLs(added, failed) {
+			state[id] = a
+		}
+	}
+
+	if len(removed) > 0 {
+		log.Printf("[INFO] Revoking %d ACLs from %s", len(removed), d.Get("principal").(string))
+		var failed map[string]error
+		err := withContext(ctx, func() (err error) {
+			failed, err = c.DeleteACLs(aclList(removed))
+			return err
+		})
+		if err != nil {
+			return append(diags, append(diag.FromErr(err), setPrincipalAccessACLs(d, state)...)...)
+		}
+
+		diags = append(diags, errorDiagnostics("Could not delete ACL", failed)...)
+		for id := range withoutFailedACLs(removed, failed) {
+			delete(state, id)
+		}
+	}
+
+	return append(diags, setPrincipalAccessACLs(d, state)...)
+}
+
+func principalAccessDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*LazyClient)
+	ctx, cancel := operationContext(ctx, d, schema.TimeoutDelete, c)
+	defer cancel()
+	acls, err := parseACLIDs(d.Get("acls").(*schema.Set))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	var failed map[string]error
+	err = withContext(ctx, func() (err error) {
+		failed, err = c.DeleteACLs(aclList(acls))
+		return err
+	})
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	if len(failed) > 0 {
+		return errorDiagnostics("Could not delete ACL", failed)
+	}
+
+	d.SetId("")
+	return nil
+}
+
+// principalAccessCustomDiff plans the ACLs the principal needs, which also
+// plans an update when some of them were deleted outside of Terraform
+func principalAccessCustomDiff(ctx context.Context, diff *schema.ResourceDiff, v interface{}) error {
+	for _, key := range append([]string{"principal", "role", "host"}, principalAccessNames...) {
+		if !diff.NewValueKnown(key) {
+			return diff.SetNewComputed("acls")
+		}
+	}
+
+	acls, err := principalAccessInfo(diff).Bindings()
+	if err != nil {
+		return err
+	}
+
+	planned, err := parseACLIDs(diff.Get("acls").(*schema.Set))
+	if err != nil {
+		return err
+	}
+
+	if added, removed := aclChanges(planned, acls); diff.Id() == "" || len(added) > 0 || len(removed) > 0 {
+		return diff.SetNew("acls", aclIDs(acls))
+	}
+	return nil
+}
+
+func principalAccessInfo(d resourceGetter) PrincipalAccess {
+	names := func(key string) []string {
+		return setToStrings(d.Get(key).(*schema.Set))
+	}
+
+	return PrincipalAccess{
+		Principal:               d.Get("principal").(string),
+		Host:                    d.Get("host").(string),
+		Role:                    d.Get("role").(string),
+		Topics:                  names("topics"),
+		TopicPrefixes:           names("topic_prefixes"),
+		Groups:                  names("groups"),
+		GroupPrefixes:           names("group_prefixes"),
+		TransactionalIDs:        names("transactional_ids"),
+		TransactionalIDPrefixes: names("transactional_id_prefixes"),
+	}
+}
+
+func setPrincipalAccessACLs(d *schema.ResourceData, acls map[string]StringlyTypedACL) diag.Diagnostics {
+	errSet := errSetter{d: d}
+	errSet.Set("acls", aclIDs(acls))
+	if errSet.err != nil {
+		return diag.FromErr(errSet.err)
+	}
+	return nil
+}
+
+func parseACLIDs(set *schema.Set) (map[string]StringlyTypedACL, error) {
+	acls := map[string]StringlyTypedACL{}
+	for _, id := range setToStrings(set) {
+		a, err := parseACLID(id)
+		if err != nil {
+			return nil, err
+		}
+		acls[id] = a
+	}
+	return acls, nil
+}
+
+func setToStrings(set *schema.Set) []string {
+	values := make([]string, 0, set.Len())
+	for _, v := range set.List() {
+		values = append(values, v.(string))
+	}
+	return values
+}
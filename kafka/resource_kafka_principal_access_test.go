@@ -0,0 +1,58 @@
+package kafka
+
+import (
+	"fmt"
+	"testing"
+
+	uuid "github.com/hashicorp/go-uuid"
+	r "github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func TestAcc_PrincipalAccess(t *testing.T) {
+	u, err := uuid.GenerateUUID()
+	if err != nil {
+		t.Fatal(err)
+	}
+	name := fmt.Sprintf("access-%s", u)
+	bs := testBootstrapServers[0]
+
+	r.Test(t, r.TestCase{
+		ProviderFactories: overrideProviderFactory(),
+		PreCheck:          func() { testAccPreCheck(t) },
+		CheckDestroy:      func(s *terraform.State) error { return testAccCheckAclDestroy(name) },
+		Steps: []r.TestStep{
+			{
+				Config: cfg(t, bs, fmt.Sprintf(testResourcePrincipalAccess_consumer, name, name)),
+				Check: r.ComposeTestCheckFunc(
+					r.TestCheckResourceAttr("kafka_principal_access.test", "acls.#", "3"),
+					testResourceACLs_check(name, []string{"Describe", "Read", "Read"}),
+				),
+			},
+			{
+				Config: cfg(t, bs, fmt.Sprintf(testResourcePrincipalAccess_producer, name)),
+				Check: r.ComposeTestCheckFunc(
+					r.TestCheckResourceAttr("kafka_principal_access.test", "acls.#", "3"),
+					testResourceACLs_check(name, []string{"Describe", "Write"}),
+				),
+			},
+		},
+	})
+}
+
+const testResourcePrincipalAccess_consumer = `
+resource "kafka_principal_access" "test" {
+  principal = "User:Alice"
+  role      = "consumer"
+  topics    = ["%s"]
+  groups    = ["%s"]
+}
+`
+
+const testResourcePrincipalAccess_producer = `
+resource "kafka_principal_access" "test" {
+  principal = "User:Alice"
+  role      = "producer"
+  topics    = ["%s"]
+}
+`
@@ -2,10 +2,14 @@ package kafka
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
+	"sort"
+	"strings"
 	"time"
 
+	"github.com/IBM/sarama"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
@@ -19,15 +23,24 @@ func kafkaTopicResource() *schema.Resource {
 		UpdateContext: topicUpdate,
 		DeleteContext: topicDelete,
 		Importer: &schema.ResourceImporter{
-			StateContext: schema.ImportStatePassthroughContext,
+			StateContext: importTopic,
 		},
 		CustomizeDiff: customDiff,
+		Timeouts:      operationTimeouts(),
+		SchemaVersion: 1,
+		MigrateState:  migrateKafkaTopicState,
 		Schema: map[string]*schema.Schema{
 			"name": {
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				Description:  "The name of the topic.",
+				ValidateFunc: validateTopicName,
+			},
+			"topic_id": {
 				Type:        schema.TypeString,
-				Required:    true,
-				ForceNew:    true,
-				Description: "The name of the topic.",
+				Computed:    true,
+				Description: "The unique ID Kafka assigned to the topic, only set by Kafka >= 2.8.0.",
 			},
 			"partitions": {
 				Type:         schema.TypeInt,
@@ -43,11 +56,58 @@ func kafkaTopicResource() *schema.Resource {
 				ValidateFunc: validation.IntAtLeast(1),
 			},
 			"config": {
+				Type:             schema.TypeMap,
+				Optional:         true,
+				ForceNew:         false,
+				Description:      "A map of string k/v attributes.",
+				Elem:             schema.TypeString,
+				ValidateFunc:     validateTopicConfigMap,
+				DiffSuppressFunc: suppressEquivalentTopicConfig,
+			},
+			"effective_config": effectiveConfigSchema(),
+			"partition_replication_factors": {
 				Type:        schema.TypeMap,
+				Computed:    true,
+				Description: "The number of replicas of each partition, only set when they differ across partitions.",
+				Elem:        &schema.Schema{Type: schema.TypeInt},
+			},
+			"elect_preferred_leaders": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Description: "Run a preferred leader election for the topic's partitions after its replicas or partitions change.",
+			},
+			"keep_reassignment_on_failure": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Description: "Leave a replica reassignment running on the cluster when it doesn't finish in time, instead of cancelling it.",
+			},
+			"partition_shrink_strategy": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "recreate",
+				Description:  "How to reduce the partitions of the topic: recreate it, deleting its records, or migrate its records to the recreated topic.",
+				ValidateFunc: validation.StringInSlice([]string{"recreate", "migrate"}, false),
+			},
+			"on_destroy": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "delete",
+				Description:  "What to do with the topic when the resource is destroyed: delete it, abandon it, or purge its records.",
+				ValidateFunc: validation.StringInSlice([]string{"delete", "abandon", "purge"}, false),
+			},
+			"ignore_config_keys": {
+				Type:        schema.TypeList,
 				Optional:    true,
-				ForceNew:    false,
-				Description: "A map of string k/v attributes.",
-				Elem:        schema.TypeString,
+				Description: "Glob patterns of configs managed outside of Terraform, which are neither read nor updated.",
+				Elem: &schema.Schema{
+					Type:         schema.TypeString,
+					ValidateFunc: validateConfigKeyPattern,
+				},
+			},
+			"pin_config": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Description: "Keep the declared configs as topic-level overrides, even when they match the value of the brokers.",
 			},
 		},
 	}
@@ -55,9 +115,11 @@ func kafkaTopicResource() *schema.Resource {
 
 func topicCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	c := meta.(*LazyClient)
+	ctx, cancel := operationContext(ctx, d, schema.TimeoutCreate, c)
+	defer cancel()
 	t := metaToTopic(d, meta)
 
-	err := c.CreateTopic(t)
+	err := c.CreateTopic(t, remainingTimeout(ctx, d.Timeout(schema.TimeoutCreate)))
 	if err != nil {
 		return diag.FromErr(err)
 	}
@@ -66,7 +128,7 @@ func topicCreate(ctx context.Context, d *schema.ResourceData, meta interface{})
 		Pending:      []string{"Pending"},
 		Target:       []string{"Created"},
 		Refresh:      topicCreateFunc(c, t),
-		Timeout:      time.Duration(c.Config.Timeout) * time.Second,
+		Timeout:      remainingTimeout(ctx, d.Timeout(schema.TimeoutCreate)),
 		Delay:        1 * time.Second,
 		PollInterval: 2 * time.Second,
 	}
@@ -76,6 +138,7 @@ func topicCreate(ctx context.Context, d *schema.ResourceData, meta interface{})
 	}
 
 	d.SetId(t.Name)
+	setTopicID(d, c, t.Name)
 	return nil
 }
 
@@ -95,14 +158,45 @@ func topicCreateFunc(client *LazyClient, t Topic) resource.StateRefreshFunc {
 
 func topicUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	c := meta.(*LazyClient)
+	ctx, cancel := operationContext(ctx, d, schema.TimeoutUpdate, c)
+	defer cancel()
 	t := metaToTopic(d, meta)
 
-	if err := c.UpdateTopic(t); err != nil {
-		return diag.FromErr(err)
+	// partitions can only decrease here with partition_shrink_strategy =
+	// "migrate", as it otherwise forces a new topic
+	if oi, ni := d.GetChange("partitions"); ni.(int) < oi.(int) {
+		log.Printf("[INFO] Migrating %s from %d to %d partitions", t.Name, oi.(int), ni.(int))
+		if err := c.MigrateTopic(t, remainingTimeout(ctx, d.Timeout(schema.TimeoutUpdate))); err != nil {
+			return diag.FromErr(err)
+		}
+
+		if err := waitForTopicRefresh(ctx, c, d.Id(), t, ignoredConfigKeys(d, c), remainingTimeout(ctx, d.Timeout(schema.TimeoutUpdate))); err != nil {
+			return diag.FromErr(err)
+		}
+
+		setTopicID(d, c, t.Name)
+
+		// the topic was recreated with the declared replication factor
+		errSet := errSetter{d: d}
+		errSet.Set("partition_replication_factors", map[string]interface{}{})
+		if errSet.err != nil {
+			return diag.FromErr(errSet.err)
+		}
+
+		return nil
+	}
+
+	if d.HasChange("config") {
+		o, n := d.GetChange("config")
+		set, remove := configChanges(o.(map[string]interface{}), n.(map[string]interface{}))
+		log.Printf("[INFO] Updating the config of %s, setting %v and removing %v", t.Name, strPtrMapToStrMap(set), remove)
+		if err := c.AlterTopicConfig(t.Name, set, remove); err != nil {
+			return diag.FromErr(err)
+		}
 	}
 
 	// update replica count of existing partitions before adding new ones
-	if d.HasChange("replication_factor") {
+	if d.HasChange("replication_factor") || d.HasChange("partition_replication_factors") {
 		oi, ni := d.GetChange("replication_factor")
 		oldRF := oi.(int)
 		newRF := ni.(int)
@@ -113,7 +207,22 @@ func topicUpdate(ctx context.Context, d *schema.ResourceData, meta interface{})
 			return diag.FromErr(err)
 		}
 
-		if err := waitForRFUpdate(ctx, c, d.Id()); err != nil {
+		if err := waitForRFUpdate(ctx, c, d.Id(), remainingTimeout(ctx, d.Timeout(schema.TimeoutUpdate))); err != nil {
+			if isReassignmentInterrupted(ctx, err) && !d.Get("keep_reassignment_on_failure").(bool) {
+				log.Printf("[WARN] Cancelling the reassignment of %s: %s", t.Name, err)
+				if cerr := c.CancelPartitionReassignments(t.Name, operationTimeout(d, schema.TimeoutUpdate, c)); cerr != nil {
+					return diag.FromErr(fmt.Errorf("%s; cancelling the reassignment also failed: %s", err, cerr))
+				}
+				setActualReplicationFactor(d, c, oldRF)
+			} else {
+				// the reassignment is still running, so the new
+				// replication factor isn't in place yet
+				errSet := errSetter{d: d}
+				errSet.Set("replication_factor", oldRF)
+				if errSet.err != nil {
+					log.Printf("[ERROR] Could not reset replication_factor: %s", errSet.err)
+				}
+			}
 			return diag.FromErr(err)
 		}
 	}
@@ -126,19 +235,85 @@ func topicUpdate(ctx context.Context, d *schema.ResourceData, meta interface{})
 		log.Printf("[INFO] Updating partitions from %d to %d", oldPartitions, newPartitions)
 		t.Partitions = int32(newPartitions)
 
-		if err := c.AddPartitions(t); err != nil {
+		if err := c.AddPartitions(t, remainingTimeout(ctx, d.Timeout(schema.TimeoutUpdate))); err != nil {
 			return diag.FromErr(err)
 		}
 	}
 
-	if err := waitForTopicRefresh(ctx, c, d.Id(), t); err != nil {
+	if err := waitForTopicRefresh(ctx, c, d.Id(), t, ignoredConfigKeys(d, c), remainingTimeout(ctx, d.Timeout(schema.TimeoutUpdate))); err != nil {
 		return diag.FromErr(err)
 	}
 
-	return nil
+	errSet := errSetter{d: d}
+	errSet.Set("partition_replication_factors", map[string]interface{}{})
+	if errSet.err != nil {
+		return diag.FromErr(errSet.err)
+	}
+
+	var diags diag.Diagnostics
+	if d.Get("elect_preferred_leaders").(bool) && (d.HasChange("replication_factor") || d.HasChange("partitions")) {
+		diags = append(diags, electPreferredLeaders(c, t.Name, nil, remainingTimeout(ctx, d.Timeout(schema.TimeoutUpdate)))...)
+	}
+
+	return diags
+}
+
+// replicationFactorWarnings warns about partitions whose replica count differs
+// from the replication_factor reported for the topic, ending the detail with
+// how the difference gets fixed.
+func replicationFactorWarnings(t Topic, fix string) diag.Diagnostics {
+	var diags diag.Diagnostics
+	if len(t.PartitionReplicationFactors) == 0 {
+		return diags
+	}
+
+	partitions := make([]int, 0, len(t.PartitionReplicationFactors))
+	for p, rf := range t.PartitionReplicationFactors {
+		if rf != t.ReplicationFactor {
+			partitions = append(partitions, int(p))
+		}
+	}
+	sort.Ints(partitions)
+
+	details := make([]string, 0, len(partitions))
+	for _, p := range partitions {
+		details = append(details, fmt.Sprintf("partition %d has %d replicas", p, t.PartitionReplicationFactors[int32(p)]))
+	}
+
+	diags = append(diags, diag.Diagnostic{
+		Severity: diag.Warning,
+		Summary:  fmt.Sprintf("Partitions of %s have different replication factors", t.Name),
+		Detail: fmt.Sprintf("replication_factor is reported as %d, the most common replica count, but %s. %s",
+			t.ReplicationFactor, strings.Join(details, ", "), fix),
+	})
+	return diags
+}
+
+// electPreferredLeaders runs a preferred leader election, reporting partitions
+// whose preferred leader could not be elected as warnings.
+func electPreferredLeaders(client *LazyClient, topic string, partitions []int32, timeout time.Duration) diag.Diagnostics {
+	failed, err := client.ElectPreferredLeaders(topic, partitions, timeout)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	return electionWarnings(topic, failed)
+}
+
+func electionWarnings(topic string, failed []ElectionError) diag.Diagnostics {
+	var diags diag.Diagnostics
+	for _, e := range failed {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Warning,
+			Summary:  fmt.Sprintf("Could not elect the preferred leader of %s-%d", topic, e.Partition),
+			Detail:   e.Error(),
+		})
+	}
+
+	return diags
 }
 
-func waitForRFUpdate(ctx context.Context, client *LazyClient, topic string) error {
+func waitForRFUpdate(ctx context.Context, client *LazyClient, topic string, timeout time.Duration) error {
 	refresh := func() (interface{}, string, error) {
 		isRFUpdating, err := client.IsReplicationFactorUpdating(topic)
 		if err != nil {
@@ -150,7 +325,6 @@ func waitForRFUpdate(ctx context.Context, client *LazyClient, topic string) erro
 		}
 	}
 
-	timeout := time.Duration(client.Config.Timeout) * time.Second
 	stateConf := &resource.StateChangeConf{
 		Pending:      []string{"Updating"},
 		Target:       []string{"Ready"},
@@ -163,19 +337,96 @@ func waitForRFUpdate(ctx context.Context, client *LazyClient, topic string) erro
 
 	if _, err := stateConf.WaitForStateContext(ctx); err != nil {
 		return fmt.Errorf(
-			"Error waiting for topic (%s) replication_factor to update: %s",
+			"Error waiting for topic (%s) replication_factor to update: %w",
 			topic, err)
 	}
 
 	return nil
 }
 
-func waitForTopicRefresh(ctx context.Context, client *LazyClient, topic string, expected Topic) error {
-	timeout := time.Duration(client.Config.Timeout) * time.Second
+// isReassignmentInterrupted reports whether waiting for a reassignment stopped
+// because it timed out or the apply was interrupted, rather than because the
+// cluster reported an error.
+func isReassignmentInterrupted(ctx context.Context, err error) bool {
+	var timeoutErr *resource.TimeoutError
+	if errors.As(err, &timeoutErr) {
+		return true
+	}
+	return ctx.Err() != nil
+}
+
+// setActualReplicationFactor stores the replication factor currently in place
+// on the cluster, falling back to fallbackRF when it can't be read.
+func setActualReplicationFactor(d *schema.ResourceData, client *LazyClient, fallbackRF int) {
+	rf := fallbackRF
+	topic, err := client.ReadTopic(d.Id(), true)
+	if err != nil {
+		log.Printf("[ERROR] Could not read replication_factor of %s after cancelling: %s", d.Id(), err)
+	} else {
+		rf = int(topic.ReplicationFactor)
+	}
+
+	errSet := errSetter{d: d}
+	errSet.Set("replication_factor", rf)
+	if errSet.err != nil {
+		log.Printf("[ERROR] Could not set replication_factor: %s", errSet.err)
+	}
+}
+
+// topicIDChange returns the ID to keep in the state, and whether it changed
+// since the last read because the topic was recreated. When the ID couldn't be
+// read, the previous one is kept so that the next read still notices it.
+func topicIDChange(previous, read string) (string, bool) {
+	if read == "" {
+		return previous, false
+	}
+	return read, previous != "" && read != previous
+}
+
+// setTopicID records the ID of a new topic, so that it being recreated
+// outside of Terraform is noticed even before the next refresh
+func setTopicID(d *schema.ResourceData, client *LazyClient, topic string) {
+	if ok, err := client.CanReadTopicIDs(); err != nil || !ok {
+		return
+	}
+
+	ids, err := client.TopicIDs([]string{topic})
+	if err != nil {
+		log.Printf("[WARN] Could not read the ID of topic %s: %s", topic, err)
+		return
+	}
+
+	if err := d.Set("topic_id", ids[topic]); err != nil {
+		log.Printf("[WARN] Could not set the ID of topic %s: %s", topic, err)
+	}
+}
+
+// ignoredConfigKeys returns the patterns of the configs ignored on the topic,
+// or on every topic
+func ignoredConfigKeys(d resourceGetter, client *LazyClient) []string {
+	patterns := []string{}
+	if client.Config != nil {
+		patterns = append(patterns, client.Config.IgnoreConfigKeys...)
+	}
+	for _, p := range d.Get("ignore_config_keys").([]interface{}) {
+		if s, ok := p.(string); ok {
+			patterns = append(patterns, s)
+		}
+	}
+	return patterns
+}
+
+// resourceGetter is implemented by both schema.ResourceData and
+// schema.ResourceDiff
+type resourceGetter interface {
+	Get(string) interface{}
+}
+
+func waitForTopicRefresh(ctx context.Context, client *LazyClient, topic string, expected Topic, ignored []string, timeout time.Duration) error {
 	stateConf := &resource.StateChangeConf{
 		Pending:      []string{"Updating"},
 		Target:       []string{"Ready"},
-		Refresh:      topicRefreshFunc(client, topic, expected),
+		Refresh:      topicRefreshFunc(client, topic, expected, ignored),
 		Timeout:      timeout,
 		Delay:        1 * time.Second,
 		PollInterval: 1 * time.Second,
@@ -191,7 +442,7 @@ func waitForTopicRefresh(ctx context.Context, client *LazyClient, topic string,
 	return nil
 }
 
-func topicRefreshFunc(client *LazyClient, topic string, expected Topic) resource.StateRefreshFunc {
+func topicRefreshFunc(client *LazyClient, topic string, expected Topic, ignored []string) resource.StateRefreshFunc {
 	return func() (result interface{}, s string, err error) {
 		log.Printf("[DEBUG] waiting for topic to update %s", topic)
 		actual, err := client.ReadTopic(topic, true)
@@ -200,6 +451,7 @@ func topicRefreshFunc(client *LazyClient, topic string, expected Topic) resource
 			return actual, "Error", err
 		}
 
+		actual.Config = withoutIgnoredConfig(actual.Config, ignored)
 		if expected.Equal(actual) {
 			return actual, "Ready", nil
 		}
@@ -210,9 +462,32 @@ func topicRefreshFunc(client *LazyClient, topic string, expected Topic) resource
 
 func topicDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	c := meta.(*LazyClient)
+	ctx, cancel := operationContext(ctx, d, schema.TimeoutDelete, c)
+	defer cancel()
 	t := metaToTopic(d, meta)
 
-	err := c.DeleteTopic(t.Name)
+	switch d.Get("on_destroy").(string) {
+	case "abandon":
+		log.Printf("[INFO] Abandoning topic %s, it is only removed from the state", t.Name)
+		d.SetId("")
+		return nil
+	case "purge":
+		log.Printf("[INFO] Purging topic %s instead of deleting it", t.Name)
+		if err := c.PurgeTopic(t.Name, remainingTimeout(ctx, d.Timeout(schema.TimeoutDelete))); err != nil {
+			return diag.FromErr(fmt.Errorf("Error purging the records of topic (%s): %s", t.Name, err))
+		}
+		d.SetId("")
+		return nil
+	}
+
+	if err := checkTopicDeletionEnabled(c, t.Name); err != nil {
+		return diag.FromErr(err)
+	}
+
+	err := c.DeleteTopic(t.Name, remainingTimeout(ctx, d.Timeout(schema.TimeoutDelete)))
+	if errors.Is(err, sarama.ErrTopicDeletionDisabled) {
+		return diag.FromErr(errTopicDeletionDisabled(t.Name))
+	}
 	if err != nil {
 		return diag.FromErr(err)
 	}
@@ -222,10 +497,9 @@ func topicDelete(ctx context.Context, d *schema.ResourceData, meta interface{})
 		Pending:      []string{"Pending"},
 		Target:       []string{"Deleted"},
 		Refresh:      topicDeleteFunc(c, d.Id(), t),
-		Timeout:      300 * time.Second,
+		Timeout:      remainingTimeout(ctx, d.Timeout(schema.TimeoutDelete)),
 		Delay:        3 * time.Second,
 		PollInterval: 2 * time.Second,
-		MinTimeout:   20 * time.Second,
 	}
 	_, err = stateConf.WaitForStateContext(ctx)
 	if err != nil {
@@ -237,6 +511,25 @@ func topicDelete(ctx context.Context, d *schema.ResourceData, meta interface{})
 	return nil
 }
 
+// checkTopicDeletionEnabled returns an error when the brokers would refuse to
+// delete the topic. The check is skipped when the brokers' config can't be
+// read, leaving it to the deletion to fail.
+func checkTopicDeletionEnabled(c *LazyClient, topic string) error {
+	enabled, err := c.TopicDeletionEnabled()
+	if err != nil {
+		log.Printf("[WARN] Could not check whether topic deletion is enabled: %s", err)
+		return nil
+	}
+	if !enabled {
+		return errTopicDeletionDisabled(topic)
+	}
+	return nil
+}
+
+func errTopicDeletionDisabled(topic string) error {
+	return fmt.Errorf("Can't delete topic %s: the cluster has delete.topic.enable=false. Set on_destroy to \"abandon\" to only remove it from the state, or to \"purge\" to delete its records", topic)
+}
+
 func topicDeleteFunc(client *LazyClient, id string, t Topic) resource.StateRefreshFunc {
 	return func() (result interface{}, s string, err error) {
 		topic, err := client.ReadTopic(t.Name, true)
@@ -253,6 +546,34 @@ func topicDeleteFunc(client *LazyClient, id string, t Topic) resource.StateRefre
 	}
 }
 
+// importTopic checks that the topic exists before importing it
+func importTopic(ctx context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	name := d.Id()
+	if strings.HasPrefix(name, topicImportPrefix) || strings.HasPrefix(name, topicImportRegex) {
+		return nil, fmt.Errorf("'%s' matches many topics, which can only be imported into a kafka_topics resource", name)
+	}
+
+	client := meta.(*LazyClient)
+	if _, err := client.ReadTopic(name, true); err != nil {
+		if _, ok := err.(TopicMissingError); ok {
+			return nil, fmt.Errorf("Failed importing topic: %s doesn't exist", name)
+		}
+		return nil, err
+	}
+
+	// the state of an imported topic has the defaults of the arguments that
+	// aren't read from the brokers, so that the next plan doesn't change them
+	errSet := errSetter{d: d}
+	for k, v := range topicStateDefaults {
+		errSet.Set(k, v)
+	}
+	if errSet.err != nil {
+		return nil, errSet.err
+	}
+
+	return []*schema.ResourceData{d}, nil
+}
+
 func topicRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	name := d.Id()
 	client := meta.(*LazyClient)
@@ -269,35 +590,120 @@ func topicRead(ctx context.Context, d *schema.ResourceData, meta interface{}) di
 		return diag.FromErr(err)
 	}
 
+	var diags diag.Diagnostics
+	if len(topic.PartitionReplicationFactors) > 0 {
+		canAlterRF, err := client.CanAlterReplicationFactor()
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		fix := "The next apply will reassign every partition to the declared replication_factor."
+		if !canAlterRF {
+			fix = "Kafka >= 2.4.0 is needed to reassign the partitions, so the next apply leaves them as they are."
+		}
+		diags = replicationFactorWarnings(topic, fix)
+	}
+
+	declared := d.Get("config").(map[string]interface{})
+	topic.Config = withoutIgnoredConfig(topic.Config, ignoredConfigKeys(d, client))
+	if client.Config.PinTopicConfigs || d.Get("pin_config").(bool) {
+		topic.Config = pinDeclaredConfig(topic.Config, topic.EffectiveConfig, declared)
+	}
+
+	previousID := d.Get("topic_id").(string)
+	var replaced bool
+	if topic.ID, replaced = topicIDChange(previousID, topic.ID); replaced {
+		log.Printf("[WARN] Topic %s was recreated, its ID changed from %s to %s", name, previousID, topic.ID)
+		topic.Config = configForReplacedTopic(topic.Config, declared)
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Warning,
+			Summary:  fmt.Sprintf("Topic %s was replaced outside of Terraform", name),
+			Detail:   fmt.Sprintf("Its ID changed from %s to %s, so its records and configs were reset. The declared configs will be applied again.", previousID, topic.ID),
+		})
+	}
+
 	log.Printf("[DEBUG] Setting the state from Kafka %v", topic)
 	errSet := errSetter{d: d}
 	errSet.Set("name", topic.Name)
+	errSet.Set("topic_id", topic.ID)
 	errSet.Set("partitions", topic.Partitions)
 	errSet.Set("replication_factor", topic.ReplicationFactor)
 	errSet.Set("config", topic.Config)
+	errSet.Set("effective_config", flattenEffectiveConfig(topic.EffectiveConfig))
+	errSet.Set("partition_replication_factors", flattenReplicationFactors(topic.PartitionReplicationFactors))
 
 	if errSet.err != nil {
 		return diag.FromErr(errSet.err)
 	}
 
-	return nil
+	return diags
 }
 
 func customDiff(ctx context.Context, diff *schema.ResourceDiff, v interface{}) error {
 	log.Printf("[INFO] Checking the diff!")
 	client := v.(*LazyClient)
 
+	if diff.HasChange("name") && diff.NewValueKnown("name") {
+		name := diff.Get("name").(string)
+		topics, err := client.Topics()
+		if err != nil {
+			// the cluster may not exist yet, e.g. when it's created in the
+			// same run, so the check is skipped rather than failing the plan
+			log.Printf("[WARN] Could not list topics to check '%s' for collisions: %s", name, err)
+		} else if err := topicNameCollision(name, topics); err != nil {
+			return err
+		}
+	}
+
+	// whether the topic is replaced under the same name
+	replaced := false
 	if diff.HasChange("partitions") {
 		log.Printf("[INFO] Partitions have changed!")
 		o, n := diff.GetChange("partitions")
 		oi := o.(int)
 		ni := n.(int)
 		log.Printf("[INFO] Partitions is changing from %d to %d", oi, ni)
-		if ni < oi {
+		if ni < oi && diff.Get("partition_shrink_strategy").(string) == "migrate" {
+			log.Printf("[INFO] Partitions decreased from %d to %d. Migrating the topic", oi, ni)
+			// the migration recreates the topic, which gives it a new ID
+			if err := diff.SetNewComputed("topic_id"); err != nil {
+				return err
+			}
+		} else if ni < oi {
 			log.Printf("Partitions decreased from %d to %d. Forcing new resource", oi, ni)
 			if err := diff.ForceNew("partitions"); err != nil {
 				return err
 			}
+			replaced = true
+		}
+	}
+
+	ignored := ignoredConfigKeys(diff, client)
+	for key := range diff.Get("config").(map[string]interface{}) {
+		if isIgnoredConfigKey(key, ignored) {
+			return fmt.Errorf("config %s is declared, but it matches ignore_config_keys", key)
+		}
+	}
+
+	if diff.HasChange("config") {
+		if err := diff.SetNewComputed("effective_config"); err != nil {
+			return err
+		}
+	}
+
+	// partitions had different replica counts when the topic was last read
+	if counts, ok := diff.Get("partition_replication_factors").(map[string]interface{}); ok && len(counts) > 0 {
+		canAlterRF, err := client.CanAlterReplicationFactor()
+		if err != nil {
+			return err
+		}
+
+		if canAlterRF {
+			// reassign every partition to the declared replication_factor
+			if err := diff.SetNewComputed("partition_replication_factors"); err != nil {
+				return err
+			}
+		} else {
+			log.Printf("[WARN] Need kafka >= 2.4.0 to reconcile the replication factor of %s's partitions", diff.Id())
 		}
 	}
 
@@ -312,8 +718,24 @@ func customDiff(ctx context.Context, diff *schema.ResourceDiff, v interface{}) e
 			if err := diff.ForceNew("replication_factor"); err != nil {
 				return err
 			}
+			replaced = true
 		}
 	}
 
+	if replaced && diff.Id() != "" && !diff.HasChange("name") {
+		// the delete of the replacement uses the on_destroy of the state
+		onDestroy, _ := diff.GetChange("on_destroy")
+		return checkReplacement(diff.Get("name").(string), onDestroy.(string))
+	}
+	return nil
+}
+
+// checkReplacement returns an error when replacing the topic would keep it,
+// so that creating it again would fail as it already exists
+func checkReplacement(topic, onDestroy string) error {
+	switch onDestroy {
+	case "abandon", "purge":
+		return fmt.Errorf("topic %s would be replaced, but on_destroy = %q keeps it, so it can't be created again. Set on_destroy to \"delete\" and apply that first, or avoid the replacement", topic, onDestroy)
+	}
 	return nil
 }
@@ -0,0 +1,277 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+var recordsDeletionTargets = []string{"partition_offsets", "before_timestamp", "latest"}
+
+func kafkaTopicRecordsDeletionResource() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: recordsDeletionCreate,
+		ReadContext:   recordsDeletionRead,
+		DeleteContext: recordsDeletionDelete,
+		CustomizeDiff: recordsDeletionCustomDiff,
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(defaultTimeout * time.Second),
+		},
+		Schema: map[string]*schema.Schema{
+			"topic": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "The name of the topic whose records should be deleted.",
+			},
+			"partition_offsets": {
+				Type:         schema.TypeMap,
+				Optional:     true,
+				ForceNew:     true,
+				ExactlyOneOf: recordsDeletionTargets,
+				Description:  "The offset of each partition before which records are deleted, keyed by partition. An offset of -1 deletes every record of the partition.",
+				Elem:         &schema.Schema{Type: schema.TypeInt},
+				ValidateFunc: validatePartitionOffsets,
+			},
+			"before_timestamp": {
+				Type:             schema.TypeString,
+				Optional:         true,
+				ForceNew:         true,
+				ExactlyOneOf:     recordsDeletionTargets,
+				Description:      "Delete the records of every partition produced before this RFC 3339 timestamp.",
+				ValidateDiagFunc: validation.ToDiagFunc(validation.IsRFC3339Time),
+			},
+			"latest": {
+				Type:         schema.TypeBool,
+				Optional:     true,
+				ForceNew:     true,
+				ExactlyOneOf: recordsDeletionTargets,
+				Description:  "Delete every record of every partition.",
+			},
+			"triggers": {
+				Type:        schema.TypeMap,
+				Optional:    true,
+				ForceNew:    true,
+				Description: "A map of arbitrary strings that, when changed, will delete the records again.",
+				Elem:        schema.TypeString,
+			},
+			"deleted_records": {
+				Type:        schema.TypeMap,
+				Computed:    true,
+				Description: "The number of records of each partition that the deletion removed.",
+				Elem:        &schema.Schema{Type: schema.TypeInt},
+			},
+			"deleted_record_count": {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "The total number of records that the deletion removed.",
+			},
+			"low_watermarks": {
+				Type:        schema.TypeMap,
+				Computed:    true,
+				Description: "The low watermark of each partition after the records were deleted.",
+				Elem:        &schema.Schema{Type: schema.TypeInt},
+			},
+		},
+	}
+}
+
+func recordsDeletionCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*LazyClient)
+	ctx, cancel := operationContext(ctx, d, schema.TimeoutCreate, c)
+	defer cancel()
+	topic := d.Get("topic").(string)
+
+	targets, err := recordsDeletionOffsets(c, d)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	previous, _, err := c.PartitionWatermarks(topic)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	lowWatermarks, err := c.DeleteRecords(topic, targets, remainingTimeout(ctx, d.Timeout(schema.TimeoutCreate)))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	log.Printf("[INFO] Deleted the records of %d partitions of %s", len(lowWatermarks), topic)
+
+	deleted := deletedRecords(previous, lowWatermarks)
+	total := int64(0)
+	for _, n := range deleted {
+		total += n
+	}
+
+	d.SetId(recordsDeletionID(topic, targets))
+	errSet := errSetter{d: d}
+	errSet.Set("low_watermarks", partitionMap(lowWatermarks))
+	errSet.Set("deleted_records", partitionMap(deleted))
+	errSet.Set("deleted_record_count", int(total))
+	if errSet.err != nil {
+		return diag.FromErr(errSet.err)
+	}
+
+	return nil
+}
+
+func recordsDeletionRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*LazyClient)
+	topic := d.Get("topic").(string)
+
+	_, err := c.ReadTopic(topic, false)
+	if err != nil {
+		if _, ok := err.(TopicMissingError); ok {
+			log.Printf("[INFO] Topic %s of the records deletion is gone", topic)
+			d.SetId("")
+			return nil
+		}
+		return diag.FromErr(err)
+	}
+
+	return nil
+}
+
+func recordsDeletionDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	// deleted records can't be restored, so this only removes it from the state
+	d.SetId("")
+	return nil
+}
+
+// recordsDeletionCustomDiff checks that the records to delete are in range of
+// the partitions. How many records are removed depends on the watermarks when
+// the deletion is applied, so it is only known after the apply.
+func recordsDeletionCustomDiff(ctx context.Context, diff *schema.ResourceDiff, v interface{}) error {
+	if diff.Id() != "" {
+		return nil
+	}
+
+	for _, key := range []string{"topic", "partition_offsets", "before_timestamp"} {
+		if !diff.NewValueKnown(key) {
+			log.Printf("[DEBUG] %s isn't known yet, the records to delete can't be planned", key)
+			return nil
+		}
+	}
+
+	c := v.(*LazyClient)
+	topic := diff.Get("topic").(string)
+
+	targets, err := recordsDeletionOffsets(c, diff)
+	if err != nil {
+		if _, ok := err.(TopicMissingError); ok {
+			log.Printf("[DEBUG] Topic %s doesn't exist yet, the records to delete can't be planned", topic)
+			return nil
+		}
+		return err
+	}
+
+	low, high, err := c.PartitionWatermarks(topic)
+	if err != nil {
+		return err
+	}
+
+	counts, err := recordsToDelete(low, high, targets)
+	if err != nil {
+		return fmt.Errorf("can't delete the records of %s: %w", topic, err)
+	}
+
+	total := int64(0)
+	for _, n := range counts {
+		total += n
+	}
+	log.Printf("[INFO] Deleting the records of %s would currently remove %d records", topic, total)
+
+	return nil
+}
+
+// recordsDeletionID identifies a deletion by its topic and the offsets it
+// deletes records before, so that deletions of the same topic don't share an
+// ID
+func recordsDeletionID(topic string, offsets map[int32]int64) string {
+	partitions := make([]int, 0, len(offsets))
+	for p := range offsets {
+		partitions = append(partitions, int(p))
+	}
+	sort.Ints(partitions)
+
+	targets := make([]string, 0, len(partitions))
+	for _, p := range partitions {
+		targets = append(targets, fmt.Sprintf("%d:%d", p, offsets[int32(p)]))
+	}
+	return topic + "|" + strings.Join(targets, ",")
+}
+
+// recordsDeletionOffsets resolves the offsets of each partition before which
+// records are deleted
+func recordsDeletionOffsets(c *LazyClient, d resourceGetter) (map[int32]int64, error) {
+	topic := d.Get("topic").(string)
+
+	if _, err := c.ReadTopic(topic, true); err != nil {
+		return nil, err
+	}
+
+	if raw := d.Get("partition_offsets").(map[string]interface{}); len(raw) > 0 {
+		offsets := make(map[int32]int64, len(raw))
+		for k, v := range raw {
+			p, err := strconv.ParseInt(k, 10, 32)
+			if err != nil {
+				return nil, fmt.Errorf("partition %s is not a number", k)
+			}
+			offsets[int32(p)] = int64(v.(int))
+		}
+		return offsets, nil
+	}
+
+	if ts := d.Get("before_timestamp").(string); ts != "" {
+		t, err := time.Parse(time.RFC3339, ts)
+		if err != nil {
+			return nil, err
+		}
+		return c.OffsetsForTimestamp(topic, t)
+	}
+
+	if !d.Get("latest").(bool) {
+		return nil, errors.New("one of partition_offsets, before_timestamp or latest = true must be set")
+	}
+
+	_, high, err := c.PartitionWatermarks(topic)
+	return high, err
+}
+
+func validatePartitionOffsets(i interface{}, k string) (warnings []string, errs []error) {
+	offsets, ok := i.(map[string]interface{})
+	if !ok {
+		return nil, []error{fmt.Errorf("expected type of %s to be a map", k)}
+	}
+
+	for key, value := range offsets {
+		if p, err := strconv.ParseInt(key, 10, 32); err != nil || p < 0 {
+			errs = append(errs, fmt.Errorf("%s: partition %q must be a non-negative number", k, key))
+		}
+		if offset, ok := value.(int); ok && offset < deleteToHighWatermark {
+			errs = append(errs, fmt.Errorf("%s: offset %d of partition %s must be at least %d", k, offset, key, deleteToHighWatermark))
+		}
+	}
+
+	return warnings, errs
+}
+
+// partitionMap converts values keyed by partition into a map that can be
+// stored in the state
+func partitionMap(values map[int32]int64) map[string]interface{} {
+	m := make(map[string]interface{}, len(values))
+	for p, v := range values {
+		m[strconv.Itoa(int(p))] = int(v)
+	}
+	return m
+}
@@ -1,8 +1,10 @@
 package kafka
 
 import (
+	"context"
 	"fmt"
 	"log"
+	"regexp"
 	"strconv"
 	"testing"
 	"time"
@@ -11,7 +13,7 @@ import (
 	r "github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 
-	"github.com/Shopify/sarama"
+	"github.com/IBM/sarama"
 )
 
 func TestAcc_BasicTopic(t *testing.T) {
@@ -59,6 +61,95 @@ func TestAcc_TopicConfigUpdate(t *testing.T) {
 	})
 }
 
+func TestAcc_TopicAbandonOnDestroy(t *testing.T) {
+	u, err := uuid.GenerateUUID()
+	if err != nil {
+		t.Fatal(err)
+	}
+	topicName := fmt.Sprintf("syslog-%s", u)
+	bs := testBootstrapServers[0]
+
+	r.Test(t, r.TestCase{
+		ProviderFactories: overrideProviderFactory(),
+		PreCheck:          func() { testAccPreCheck(t) },
+		CheckDestroy:      testAccCheckTopicAbandoned(topicName),
+		Steps: []r.TestStep{
+			{
+				Config: cfg(t, bs, fmt.Sprintf(testResourceTopic_abandonOnDestroy, topicName)),
+				Check:  r.TestCheckResourceAttr("kafka_topic.test", "on_destroy", "abandon"),
+			},
+		},
+	})
+}
+
+func testAccCheckTopicAbandoned(name string) r.TestCheckFunc {
+	return func(s *terraform.State) error {
+		client := testProvider.Meta().(*LazyClient)
+		if _, err := client.ReadTopic(name, true); err != nil {
+			return fmt.Errorf("expected %s to be kept: %s", name, err)
+		}
+
+		return client.DeleteTopic(name, 30*time.Second)
+	}
+}
+
+func TestAcc_TopicAbandonOnDestroyReplace(t *testing.T) {
+	u, err := uuid.GenerateUUID()
+	if err != nil {
+		t.Fatal(err)
+	}
+	topicName := fmt.Sprintf("syslog-%s", u)
+	bs := testBootstrapServers[0]
+
+	r.Test(t, r.TestCase{
+		ProviderFactories: overrideProviderFactory(),
+		PreCheck:          func() { testAccPreCheck(t) },
+		CheckDestroy:      testAccCheckTopicAbandoned(topicName),
+		Steps: []r.TestStep{
+			{
+				Config: cfg(t, bs, fmt.Sprintf(testResourceTopic_abandonOnDestroy, topicName)),
+			},
+			{
+				// decreasing the partitions replaces the topic
+				Config:      cfg(t, bs, fmt.Sprintf(testResourceTopic_abandonOnDestroyShrink, topicName)),
+				ExpectError: regexp.MustCompile("on_destroy = \"abandon\" keeps it"),
+			},
+		},
+	})
+}
+
+func Test_CheckReplacement(t *testing.T) {
+	for _, onDestroy := range []string{"delete", ""} {
+		if err := checkReplacement("syslog", onDestroy); err != nil {
+			t.Errorf("Expected replacing a topic with on_destroy = %q to be allowed, got %s", onDestroy, err)
+		}
+	}
+	for _, onDestroy := range []string{"abandon", "purge"} {
+		if err := checkReplacement("syslog", onDestroy); err == nil {
+			t.Errorf("Expected replacing a topic with on_destroy = %q to fail", onDestroy)
+		}
+	}
+}
+
+func Test_IsReassignmentInterrupted(t *testing.T) {
+	ctx := context.Background()
+
+	timeout := fmt.Errorf("waiting: %w", &r.TimeoutError{Timeout: time.Second})
+	if !isReassignmentInterrupted(ctx, timeout) {
+		t.Errorf("expected a timeout to interrupt the reassignment")
+	}
+
+	if isReassignmentInterrupted(ctx, fmt.Errorf("broker failure")) {
+		t.Errorf("expected a broker error not to interrupt the reassignment")
+	}
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	if !isReassignmentInterrupted(cancelled, cancelled.Err()) {
+		t.Errorf("expected a cancelled context to interrupt the reassignment")
+	}
+}
+
 func testAccCheckTopicDestroy(s *terraform.State) error {
 	resourceState := s.Modules[0].Resources["kafka_topic.test"]
 	if resourceState == nil {
@@ -514,3 +605,21 @@ resource "kafka_topic" "test" {
   }
 }
 `
+
+const testResourceTopic_abandonOnDestroy = `
+resource "kafka_topic" "test" {
+  name               = "%s"
+  replication_factor = 1
+  partitions         = 2
+  on_destroy         = "abandon"
+}
+`
+
+const testResourceTopic_abandonOnDestroyShrink = `
+resource "kafka_topic" "test" {
+  name               = "%s"
+  replication_factor = 1
+  partitions         = 1
+  on_destroy         = "abandon"
+}
+`
@@ -0,0 +1,720 @@
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/IBM/sarama"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+func kafkaTopicsResource() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: topicsCreate,
+		ReadContext:   topicsRead,
+		UpdateContext: topicsUpdate,
+		DeleteContext: topicsDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: importTopics,
+		},
+		CustomizeDiff: topicsCustomDiff,
+		Timeouts:      operationTimeouts(),
+		Schema: map[string]*schema.Schema{
+			"topics": {
+				Type:             schema.TypeMap,
+				Required:         true,
+				Description:      "The JSON encoded spec of each topic to manage, keyed by topic name.",
+				Elem:             &schema.Schema{Type: schema.TypeString},
+				ValidateFunc:     validateTopicSpecs,
+				DiffSuppressFunc: topicSpecsEquivalent,
+			},
+			"on_destroy": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "delete",
+				Description:  "What to do with the topics that are removed, or when the resource is destroyed: delete them, abandon them, or purge their records.",
+				ValidateFunc: validation.StringInSlice([]string{"delete", "abandon", "purge"}, false),
+			},
+		},
+	}
+}
+
+func topicsCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*LazyClient)
+	ctx, cancel := operationContext(ctx, d, schema.TimeoutCreate, c)
+	defer cancel()
+	topics, err := expandTopics(d.Get("topics").(map[string]interface{}))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	actual, created, diags := reconcileTopics(ctx, c, map[string]Topic{}, topics, "delete", d.Timeout(schema.TimeoutCreate))
+	if diags.HasError() {
+		// Terraform taints resources whose creation fails, and replacing
+		// this one would delete every topic, so the topics this create made
+		// are deleted instead and the whole create can be retried. Topics
+		// that already existed, and failed with TopicAlreadyExists, are kept.
+		diags = append(diags, rollbackTopics(c, created, operationTimeout(d, schema.TimeoutDelete, c))...)
+		return diags
+	}
+
+	d.SetId(resource.UniqueId())
+	return append(diags, setTopicsState(d, actual)...)
+}
+
+func rollbackTopics(c *LazyClient, names []string, timeout time.Duration) diag.Diagnostics {
+	if len(names) == 0 {
+		return nil
+	}
+
+	log.Printf("[WARN] Deleting the %d topics created before the failure", len(names))
+	failed, err := c.DeleteTopics(names, timeout)
+	if err != nil {
+		return diag.Errorf("could not delete the topics created before the failure (%v): %s", names, err)
+	}
+
+	return errorDiagnostics("Could not delete topic created before the failure", failed)
+}
+
+// importTopics imports every existing topic matching an ID like
+// "prefix:orders." or "regex:^payments-.*$"
+func importTopics(ctx context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	c := meta.(*LazyClient)
+	existing, err := c.Topics()
+	if err != nil {
+		return nil, err
+	}
+
+	names, err := matchTopics(d.Id(), existing)
+	if err != nil {
+		return nil, err
+	}
+
+	topics, err := readTopics(c, names)
+	if err != nil {
+		return nil, err
+	}
+	if len(topics) == 0 {
+		return nil, fmt.Errorf("no topics match '%s'", d.Id())
+	}
+	log.Printf("[INFO] Importing %d topics matching '%s'", len(topics), d.Id())
+
+	d.SetId(resource.UniqueId())
+	errSet := errSetter{d: d}
+	errSet.Set("on_destroy", "delete")
+	if errSet.err != nil {
+		return nil, errSet.err
+	}
+	if diags := setTopicsState(d, topics); diags.HasError() {
+		return nil, fmt.Errorf("%s", diags[0].Summary)
+	}
+
+	return []*schema.ResourceData{d}, nil
+}
+
+func topicsRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*LazyClient)
+	declared, err := expandTopics(d.Get("topics").(map[string]interface{}))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	actual, err := readTopics(c, topicNames(declared))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	diags := setTopicsState(d, actual)
+	for _, name := range topicNames(actual) {
+		diags = append(diags, replicationFactorWarnings(actual[name],
+			"kafka_topics doesn't reassign partitions; manage the topic with a kafka_topic resource to fix it.")...)
+	}
+
+	return diags
+}
+
+// readTopics reads the topics, leaving out the configs that match the
+// provider's ignore_config_keys
+func readTopics(c *LazyClient, names []string) (map[string]Topic, error) {
+	topics, err := c.ReadTopics(names)
+	if err != nil || c.Config == nil {
+		return topics, err
+	}
+
+	for name, t := range topics {
+		t.Config = withoutIgnoredConfig(t.Config, c.Config.IgnoreConfigKeys)
+		topics[name] = t
+	}
+	return topics, nil
+}
+
+func topicsUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*LazyClient)
+	ctx, cancel := operationContext(ctx, d, schema.TimeoutUpdate, c)
+	defer cancel()
+	o, n := d.GetChange("topics")
+	old, err := expandTopics(o.(map[string]interface{}))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	topics, err := expandTopics(n.(map[string]interface{}))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	actual, _, diags := reconcileTopics(ctx, c, old, topics, d.Get("on_destroy").(string), d.Timeout(schema.TimeoutUpdate))
+	return append(diags, setTopicsState(d, actual)...)
+}
+
+func topicsDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*LazyClient)
+	ctx, cancel := operationContext(ctx, d, schema.TimeoutDelete, c)
+	defer cancel()
+	topics, err := expandTopics(d.Get("topics").(map[string]interface{}))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	names := topicNames(topics)
+
+	onDestroy := d.Get("on_destroy").(string)
+	failed, err := destroyTopics(ctx, c, names, onDestroy, d.Timeout(schema.TimeoutDelete))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	if len(failed) > 0 {
+		return errorDiagnostics("Could not delete topic", failed)
+	}
+	if onDestroy != "delete" {
+		d.SetId("")
+		return nil
+	}
+
+	stateConf := &resource.StateChangeConf{
+		Pending: []string{"Pending"},
+		Target:  []string{"Deleted"},
+		Refresh: func() (interface{}, string, error) {
+			remaining, err := c.ReadTopics(names)
+			if err != nil {
+				return nil, "Error", err
+			}
+			if len(remaining) > 0 {
+				log.Printf("[DEBUG] Waiting for %d topics to be deleted", len(remaining))
+				return remaining, "Pending", nil
+			}
+			return remaining, "Deleted", nil
+		},
+		Timeout:      remainingTimeout(ctx, d.Timeout(schema.TimeoutDelete)),
+		Delay:        3 * time.Second,
+		PollInterval: 5 * time.Second,
+		MinTimeout:   20 * time.Second,
+	}
+
+	if _, err := stateConf.WaitForStateContext(ctx); err != nil {
+		return diag.FromErr(fmt.Errorf("error waiting for topics to be deleted: %s", err))
+	}
+
+	d.SetId("")
+	return nil
+}
+
+// destroyTopics applies on_destroy to topics that are no longer managed,
+// deleting them, purging their records or leaving them alone. Deleting them
+// fails early when the brokers have topic deletion disabled.
+func destroyTopics(ctx context.Context, c *LazyClient, names []string, onDestroy string, timeout time.Duration) (map[string]error, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+
+	switch onDestroy {
+	case "abandon":
+		log.Printf("[INFO] Abandoning %d topics, they are only removed from the state", len(names))
+		return nil, nil
+	case "purge":
+		log.Printf("[INFO] Purging %d topics instead of deleting them", len(names))
+		failed := map[string]error{}
+		for _, name := range names {
+			if err := c.PurgeTopic(name, remainingTimeout(ctx, timeout)); err != nil {
+				failed[name] = fmt.Errorf("purging its records: %w", err)
+			}
+		}
+		return failed, nil
+	}
+
+	if err := checkTopicDeletionEnabled(c, strings.Join(names, ", ")); err != nil {
+		return nil, err
+	}
+
+	failed, err := c.DeleteTopics(names, remainingTimeout(ctx, timeout))
+	for name, ferr := range failed {
+		if errors.Is(ferr, sarama.ErrTopicDeletionDisabled) {
+			failed[name] = errTopicDeletionDisabled(name)
+		}
+	}
+	return failed, err
+}
+
+// destroyedTopicNames returns the names of the topics that on_destroy removed
+// from the resource without deleting them
+func destroyedTopicNames(names []string, onDestroy string, failed map[string]error) []string {
+	destroyed := []string{}
+	if onDestroy == "delete" {
+		return destroyed
+	}
+	for _, name := range names {
+		if _, ok := failed[name]; !ok {
+			destroyed = append(destroyed, name)
+		}
+	}
+	return destroyed
+}
+
+// reconcileTopics creates, updates and deletes topics so that the topics in
+// old match the ones in new, with one request per kind of change. Topics that
+// fail are reported as errors without stopping the others. It returns the
+// topics that exist once the changes are done, and the names of the topics it
+// created. Removed topics are destroyed according to onDestroy.
+func reconcileTopics(ctx context.Context, c *LazyClient, old, new map[string]Topic, onDestroy string, timeout time.Duration) (map[string]Topic, []string, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	added, altered, grown, removed := topicChanges(old, new)
+	log.Printf("[INFO] Creating %d topics, updating the config of %d, the partitions of %d and deleting %d",
+		len(added), len(altered), len(grown), len(removed))
+
+	// validate the new topics first, so that an invalid one doesn't leave
+	// the others half applied
+	if len(added) > 0 {
+		canValidate, err := c.CanValidateTopics()
+		if err != nil {
+			return old, nil, diag.FromErr(err)
+		}
+		if canValidate {
+			failed, err := c.CreateTopics(added, true, remainingTimeout(ctx, timeout))
+			if err != nil {
+				return old, nil, diag.FromErr(err)
+			}
+			if len(failed) > 0 {
+				return old, nil, errorDiagnostics("Invalid topic", failed)
+			}
+		}
+	}
+
+	var created, destroyed []string
+	steps := []struct {
+		summary string
+		apply   func() (map[string]error, error)
+	}{
+		{"Could not delete topic", func() (map[string]error, error) {
+			failed, err := destroyTopics(ctx, c, removed, onDestroy, timeout)
+			if err == nil {
+				destroyed = destroyedTopicNames(removed, onDestroy, failed)
+			}
+			return failed, err
+		}},
+		{"Could not create topic", func() (map[string]error, error) {
+			failed, err := c.CreateTopics(added, false, remainingTimeout(ctx, timeout))
+			if err == nil {
+				created = createdTopicNames(added, failed)
+			}
+			return failed, err
+		}},
+		{"Could not update the config of topic", func() (map[string]error, error) {
+			return c.UpdateTopics(old, altered)
+		}},
+		{"Could not add partitions to topic", func() (map[string]error, error) {
+			return c.AddPartitionsToTopics(grown, remainingTimeout(ctx, timeout))
+		}},
+	}
+
+	for _, step := range steps {
+		stepFailed, err := step.apply()
+		if err != nil {
+			diags = append(diags, diag.Errorf("%s: %s", step.summary, err)...)
+			break
+		}
+		diags = append(diags, errorDiagnostics(step.summary, stepFailed)...)
+	}
+
+	// topics that are kept by on_destroy are no longer read
+	names := []string{}
+	for _, name := range topicNames(old) {
+		if !stringInSlice(name, destroyed) {
+			names = append(names, name)
+		}
+	}
+	for name := range new {
+		if _, ok := old[name]; !ok {
+			names = append(names, name)
+		}
+	}
+
+	deleting := removed
+	if onDestroy != "delete" {
+		deleting = nil
+	}
+
+	if diags.HasError() {
+		// don't wait for topics that may never get to the desired state
+		actual, err := readTopics(c, names)
+		if err != nil {
+			return old, created, append(diags, diag.FromErr(err)...)
+		}
+		return actual, created, diags
+	}
+
+	var actual map[string]Topic
+	stateConf := &resource.StateChangeConf{
+		Pending: []string{"Updating"},
+		Target:  []string{"Ready"},
+		Refresh: func() (interface{}, string, error) {
+			var err error
+			actual, err = readTopics(c, names)
+			if err != nil {
+				return nil, "Error", err
+			}
+			if pending := pendingTopics(actual, new, deleting); len(pending) > 0 {
+				log.Printf("[DEBUG] Waiting for %d topics to be updated: %v", len(pending), pending)
+				return actual, "Updating", nil
+			}
+			return actual, "Ready", nil
+		},
+		Timeout:      remainingTimeout(ctx, timeout),
+		Delay:        1 * time.Second,
+		PollInterval: 2 * time.Second,
+		MinTimeout:   2 * time.Second,
+	}
+
+	if _, err := stateConf.WaitForStateContext(ctx); err != nil {
+		diags = append(diags, diag.Errorf("error waiting for topics to be updated: %s", err)...)
+		if actual == nil {
+			return old, created, diags
+		}
+	}
+
+	return actual, created, diags
+}
+
+// createdTopicNames returns the names of the topics of a CreateTopics request
+// that didn't fail
+func createdTopicNames(added []Topic, failed map[string]error) []string {
+	names := []string{}
+	for _, t := range added {
+		if _, ok := failed[t.Name]; !ok {
+			names = append(names, t.Name)
+		}
+	}
+	return names
+}
+
+// topicChanges compares the topics before and after a change, returning the
+// topics to create, the topics whose config or partitions changed, and the
+// names of the topics to delete.
+func topicChanges(old, new map[string]Topic) (added, altered, grown []Topic, removed []string) {
+	for _, name := range topicNames(new) {
+		t := new[name]
+		o, ok := old[name]
+		if !ok {
+			added = append(added, t)
+			continue
+		}
+
+		if MapEq(o.Config, t.Config) != nil {
+			altered = append(altered, t)
+		}
+		if t.Partitions > o.Partitions {
+			grown = append(grown, t)
+		}
+	}
+
+	for _, name := range topicNames(old) {
+		if _, ok := new[name]; !ok {
+			removed = append(removed, name)
+		}
+	}
+
+	return added, altered, grown, removed
+}
+
+// pendingTopics returns the names of the topics that aren't in their expected
+// state yet, or that should be gone but still exist.
+func pendingTopics(actual, expected map[string]Topic, removed []string) []string {
+	pending := []string{}
+	for _, name := range topicNames(expected) {
+		a, ok := actual[name]
+		if !ok || !a.Equal(expected[name]) {
+			pending = append(pending, name)
+		}
+	}
+
+	for _, name := range removed {
+		if _, ok := actual[name]; ok {
+			pending = append(pending, name)
+		}
+	}
+
+	return pending
+}
+
+func topicsCustomDiff(ctx context.Context, diff *schema.ResourceDiff, v interface{}) error {
+	if !diff.HasChange("topics") || !diff.NewValueKnown("topics") {
+		return nil
+	}
+
+	o, n := diff.GetChange("topics")
+	old, err := expandTopics(o.(map[string]interface{}))
+	if err != nil {
+		return err
+	}
+	topics, err := expandTopics(n.(map[string]interface{}))
+	if err != nil {
+		return err
+	}
+	names := topicNames(topics)
+
+	client := v.(*LazyClient)
+	for name, t := range topics {
+		if client.Config != nil {
+			for key := range t.Config {
+				if isIgnoredConfigKey(key, client.Config.IgnoreConfigKeys) {
+					return fmt.Errorf("config %s of topic %s is declared, but it matches the provider's ignore_config_keys", key, name)
+				}
+			}
+		}
+
+		o, ok := old[name]
+		if !ok {
+			continue
+		}
+
+		if t.Partitions < o.Partitions {
+			return fmt.Errorf("the partitions of topic %s can't be decreased from %d to %d; remove the topic and add it again to recreate it",
+				name, o.Partitions, t.Partitions)
+		}
+		if t.ReplicationFactor != o.ReplicationFactor {
+			return fmt.Errorf("the replication_factor of topic %s can't be changed by kafka_topics, manage it with a kafka_topic resource instead", name)
+		}
+	}
+
+	existing, err := client.Topics()
+	if err != nil {
+		log.Printf("[WARN] Could not list topics to check the new topics for collisions: %s", err)
+		existing = nil
+	}
+
+	for _, name := range names {
+		if _, ok := old[name]; ok {
+			continue
+		}
+
+		others := append([]string{}, existing...)
+		for _, other := range names {
+			if other != name {
+				others = append(others, other)
+			}
+		}
+		if err := topicNameCollision(name, others); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// topicSpec is the JSON encoded spec of a topic of kafka_topics. Config
+// values may be encoded as strings, numbers or booleans.
+type topicSpec struct {
+	Partitions        int32                  `json:"partitions"`
+	ReplicationFactor int16                  `json:"replication_factor"`
+	Config            map[string]interface{} `json:"config,omitempty"`
+}
+
+// parseTopicSpec decodes the JSON encoded spec of a topic
+func parseTopicSpec(name, raw string) (Topic, error) {
+	dec := json.NewDecoder(strings.NewReader(raw))
+	dec.UseNumber()
+	dec.DisallowUnknownFields()
+
+	var spec topicSpec
+	if err := dec.Decode(&spec); err != nil {
+		return Topic{}, fmt.Errorf("the spec of topic %s is not valid: %w", name, err)
+	}
+
+	config := map[string]*string{}
+	for k, v := range spec.Config {
+		var value string
+		switch v := v.(type) {
+		case string:
+			value = v
+		case json.Number:
+			value = v.String()
+		case bool:
+			value = strconv.FormatBool(v)
+		default:
+			return Topic{}, fmt.Errorf("config %s of topic %s must be a string, number or boolean", k, name)
+		}
+		config[k] = &value
+	}
+
+	return Topic{
+		Name:              name,
+		Partitions:        spec.Partitions,
+		ReplicationFactor: spec.ReplicationFactor,
+		Config:            config,
+	}, nil
+}
+
+// encodeTopicSpec encodes the spec of a topic read from Kafka
+func encodeTopicSpec(t Topic) (string, error) {
+	spec := topicSpec{
+		Partitions:        t.Partitions,
+		ReplicationFactor: t.ReplicationFactor,
+		Config:            map[string]interface{}{},
+	}
+	for k, v := range t.Config {
+		if v != nil {
+			spec.Config[k] = *v
+		}
+	}
+
+	raw, err := json.Marshal(spec)
+	return string(raw), err
+}
+
+func validateTopicSpecs(i interface{}, k string) (warnings []string, errs []error) {
+	specs, ok := i.(map[string]interface{})
+	if !ok {
+		return nil, []error{fmt.Errorf("expected type of %s to be a map", k)}
+	}
+
+	for name, raw := range specs {
+		key := fmt.Sprintf("%s[%q]", k, name)
+		w, e := validateTopicName(name, key)
+		warnings = append(warnings, w...)
+		errs = append(errs, e...)
+
+		s, ok := raw.(string)
+		if !ok {
+			// unknown until the apply
+			continue
+		}
+		t, err := parseTopicSpec(name, s)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if t.Partitions < 1 {
+			errs = append(errs, fmt.Errorf("%s: partitions must be at least 1, got %d", key, t.Partitions))
+		}
+		if t.ReplicationFactor < 1 {
+			errs = append(errs, fmt.Errorf("%s: replication_factor must be at least 1, got %d", key, t.ReplicationFactor))
+		}
+
+		config := map[string]interface{}{}
+		for ck, cv := range t.Config {
+			config[ck] = *cv
+		}
+		w, e = validateTopicConfigMap(config, key+".config")
+		warnings = append(warnings, w...)
+		errs = append(errs, e...)
+	}
+
+	return warnings, errs
+}
+
+// topicSpecsEquivalent suppresses the diff of a topic whose spec only differs
+// in its encoding, or in config values that Kafka treats as the same
+func topicSpecsEquivalent(k, old, new string, d *schema.ResourceData) bool {
+	name := strings.TrimPrefix(k, "topics.")
+	if name == "%" || old == "" || new == "" {
+		return false
+	}
+
+	o, err := parseTopicSpec(name, old)
+	if err != nil {
+		return false
+	}
+	n, err := parseTopicSpec(name, new)
+	if err != nil {
+		return false
+	}
+	return o.Equal(n)
+}
+
+func expandTopics(specs map[string]interface{}) (map[string]Topic, error) {
+	topics := map[string]Topic{}
+	for name, raw := range specs {
+		t, err := parseTopicSpec(name, raw.(string))
+		if err != nil {
+			return nil, err
+		}
+		topics[name] = t
+	}
+	return topics, nil
+}
+
+// flattenTopics converts the topics read from Kafka to the JSON encoded specs
+// of the schema. The declared spec of a topic is kept when the topic still
+// matches it, so that it doesn't produce a diff.
+func flattenTopics(actual map[string]Topic, declared map[string]interface{}) (map[string]interface{}, error) {
+	flattened := make(map[string]interface{}, len(actual))
+	for _, name := range topicNames(actual) {
+		t := actual[name]
+
+		if raw, ok := declared[name].(string); ok {
+			if d, err := parseTopicSpec(name, raw); err == nil && d.Equal(t) {
+				flattened[name] = raw
+				continue
+			}
+		}
+
+		spec, err := encodeTopicSpec(t)
+		if err != nil {
+			return nil, err
+		}
+		flattened[name] = spec
+	}
+	return flattened, nil
+}
+
+func setTopicsState(d *schema.ResourceData, actual map[string]Topic) diag.Diagnostics {
+	topics, err := flattenTopics(actual, d.Get("topics").(map[string]interface{}))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	errSet := errSetter{d: d}
+	errSet.Set("topics", topics)
+	if errSet.err != nil {
+		return diag.FromErr(errSet.err)
+	}
+	return nil
+}
+
+func errorDiagnostics(summary string, failed map[string]error) diag.Diagnostics {
+	var diags diag.Diagnostics
+	for _, name := range sortedErrors(failed) {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  fmt.Sprintf("%s %s", summary, name),
+			Detail:   failed[name].Error(),
+		})
+	}
+	return diags
+}
+
+func topicNames(topics map[string]Topic) []string {
+	names := make([]string, 0, len(topics))
+	for name := range topics {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
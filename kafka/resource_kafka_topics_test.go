@@ -0,0 +1,292 @@
+package kafka
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/IBM/sarama"
+	uuid "github.com/hashicorp/go-uuid"
+	r "github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func TestAcc_BulkTopics(t *testing.T) {
+	u, err := uuid.GenerateUUID()
+	if err != nil {
+		t.Fatal(err)
+	}
+	prefix := fmt.Sprintf("bulk-%s", u)
+	bs := testBootstrapServers[0]
+
+	r.Test(t, r.TestCase{
+		ProviderFactories: overrideProviderFactory(),
+		PreCheck:          func() { testAccPreCheck(t) },
+		CheckDestroy:      testAccCheckTopicsDestroy(prefix),
+		Steps: []r.TestStep{
+			{
+				Config: cfg(t, bs, fmt.Sprintf(testResourceTopics_initial, prefix, prefix)),
+				Check: r.ComposeTestCheckFunc(
+					r.TestCheckResourceAttr("kafka_topics.test", "topics.%", "2"),
+					testResourceTopics_check(prefix+"-a", 1, "100000"),
+					testResourceTopics_check(prefix+"-b", 1, ""),
+				),
+			},
+			{
+				Config: cfg(t, bs, fmt.Sprintf(testResourceTopics_updated, prefix, prefix)),
+				Check: r.ComposeTestCheckFunc(
+					r.TestCheckResourceAttr("kafka_topics.test", "topics.%", "2"),
+					testResourceTopics_check(prefix+"-a", 2, "200000"),
+					testResourceTopics_check(prefix+"-c", 1, ""),
+				),
+			},
+		},
+	})
+}
+
+func testResourceTopics_check(name string, partitions int32, retention string) r.TestCheckFunc {
+	return func(s *terraform.State) error {
+		client := testProvider.Meta().(*LazyClient)
+		topic, err := client.ReadTopic(name, true)
+		if err != nil {
+			return err
+		}
+
+		if topic.Partitions != partitions {
+			return fmt.Errorf("expected %d partitions for %s, got %d", partitions, name, topic.Partitions)
+		}
+
+		actual := ""
+		if v, ok := topic.Config["retention.ms"]; ok {
+			actual = *v
+		}
+		if actual != retention {
+			return fmt.Errorf("expected retention.ms of %s to be '%s', got '%s'", name, retention, actual)
+		}
+
+		return nil
+	}
+}
+
+func testAccCheckTopicsDestroy(prefix string) r.TestCheckFunc {
+	return func(s *terraform.State) error {
+		client := testProvider.Meta().(*LazyClient)
+		names := []string{prefix + "-a", prefix + "-b", prefix + "-c"}
+		topics, err := client.ReadTopics(names)
+		if err != nil {
+			return err
+		}
+
+		if len(topics) > 0 {
+			return fmt.Errorf("expected the topics to be deleted, found %v", topicNames(topics))
+		}
+
+		return nil
+	}
+}
+
+func Test_TopicChanges(t *testing.T) {
+	retention := "100000"
+	old := map[string]Topic{
+		"a": {Name: "a", Partitions: 1, ReplicationFactor: 1, Config: map[string]*string{}},
+		"b": {Name: "b", Partitions: 1, ReplicationFactor: 1, Config: map[string]*string{}},
+		"c": {Name: "c", Partitions: 1, ReplicationFactor: 1, Config: map[string]*string{}},
+	}
+	new := map[string]Topic{
+		"a": {Name: "a", Partitions: 1, ReplicationFactor: 1, Config: map[string]*string{}},
+		"b": {Name: "b", Partitions: 3, ReplicationFactor: 1, Config: map[string]*string{"retention.ms": &retention}},
+		"d": {Name: "d", Partitions: 1, ReplicationFactor: 1, Config: map[string]*string{}},
+	}
+
+	added, altered, grown, removed := topicChanges(old, new)
+
+	if !reflect.DeepEqual(topicListNames(added), []string{"d"}) {
+		t.Errorf("expected d to be added, got %v", topicListNames(added))
+	}
+	if !reflect.DeepEqual(topicListNames(altered), []string{"b"}) {
+		t.Errorf("expected the config of b to change, got %v", topicListNames(altered))
+	}
+	if !reflect.DeepEqual(topicListNames(grown), []string{"b"}) {
+		t.Errorf("expected b to get more partitions, got %v", topicListNames(grown))
+	}
+	if !reflect.DeepEqual(removed, []string{"c"}) {
+		t.Errorf("expected c to be removed, got %v", removed)
+	}
+}
+
+func Test_CreatedTopicNames(t *testing.T) {
+	added := []Topic{{Name: "a"}, {Name: "b"}, {Name: "c"}}
+	failed := map[string]error{"b": sarama.ErrTopicAlreadyExists}
+
+	// b existed before the create, so it mustn't be rolled back
+	if got := createdTopicNames(added, failed); !reflect.DeepEqual(got, []string{"a", "c"}) {
+		t.Errorf("Got %v, expected [a c]", got)
+	}
+}
+
+func Test_PendingTopics(t *testing.T) {
+	expected := map[string]Topic{
+		"a": {Name: "a", Partitions: 1, ReplicationFactor: 1, Config: map[string]*string{}},
+		"b": {Name: "b", Partitions: 3, ReplicationFactor: 1, Config: map[string]*string{}},
+		"c": {Name: "c", Partitions: 1, ReplicationFactor: 1, Config: map[string]*string{}},
+	}
+	actual := map[string]Topic{
+		"a": {Name: "a", Partitions: 1, ReplicationFactor: 1, Config: map[string]*string{}},
+		"b": {Name: "b", Partitions: 1, ReplicationFactor: 1, Config: map[string]*string{}},
+		"d": {Name: "d", Partitions: 1, ReplicationFactor: 1, Config: map[string]*string{}},
+	}
+
+	pending := pendingTopics(actual, expected, []string{"d", "e"})
+	if !reflect.DeepEqual(pending, []string{"b", "c", "d"}) {
+		t.Errorf("Got %v, expected [b c d]", pending)
+	}
+}
+
+func Test_ParseTopicSpec(t *testing.T) {
+	topic, err := parseTopicSpec("a", `{"partitions":2,"replication_factor":3,"config":{"retention.ms":100000,"preallocate":true,"cleanup.policy":"compact"}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	retention := "100000"
+	preallocate := "true"
+	policy := "compact"
+	expected := Topic{Name: "a", Partitions: 2, ReplicationFactor: 3, Config: map[string]*string{
+		"retention.ms":   &retention,
+		"preallocate":    &preallocate,
+		"cleanup.policy": &policy,
+	}}
+	if !expected.Equal(topic) {
+		t.Errorf("Got %v, expected %v", topic, expected)
+	}
+
+	for _, raw := range []string{
+		`{"partitions":2,"replication_factor":3,"configs":{}}`,
+		`{"partitions":2,"replication_factor":3,"config":{"retention.ms":[1]}}`,
+		`not json`,
+	} {
+		if _, err := parseTopicSpec("a", raw); err == nil {
+			t.Errorf("Expected an error for %s", raw)
+		}
+	}
+}
+
+func Test_ValidateTopicSpecs(t *testing.T) {
+	_, errs := validateTopicSpecs(map[string]interface{}{
+		"a": `{"partitions":1,"replication_factor":1,"config":{"retention.ms":"100000"}}`,
+	}, "topics")
+	if len(errs) != 0 {
+		t.Errorf("Expected a valid spec, got %v", errs)
+	}
+
+	_, errs = validateTopicSpecs(map[string]interface{}{
+		"a":    `{"partitions":0,"replication_factor":1}`,
+		"b":    `{"partitions":1,"replication_factor":1,"config":{"retention.ms":"soon"}}`,
+		"c/d":  `{"partitions":1,"replication_factor":1}`,
+		"e":    `{"partitions":1}`,
+		"json": `{`,
+	}, "topics")
+	if len(errs) != 5 {
+		t.Errorf("Expected 5 errors, got %v", errs)
+	}
+}
+
+func Test_TopicSpecsEquivalent(t *testing.T) {
+	declared := `{"partitions":2,"replication_factor":3,"config":{"cleanup.policy":"delete, compact","retention.ms":100000}}`
+	actual := `{"config":{"cleanup.policy":"compact,delete","retention.ms":"100000"},"partitions":2,"replication_factor":3}`
+	if !topicSpecsEquivalent("topics.a", actual, declared, nil) {
+		t.Error("Expected equivalent specs to suppress the diff")
+	}
+
+	grown := `{"partitions":3,"replication_factor":3,"config":{"cleanup.policy":"delete, compact","retention.ms":100000}}`
+	if topicSpecsEquivalent("topics.a", actual, grown, nil) {
+		t.Error("Expected a change of partitions to be a diff")
+	}
+	if topicSpecsEquivalent("topics.a", "", declared, nil) {
+		t.Error("Expected a new topic to be a diff")
+	}
+}
+
+func Test_FlattenTopicsKeepsDeclaredSpecs(t *testing.T) {
+	policy := "compact,delete"
+	retention := "100000"
+	actual := map[string]Topic{
+		"a": {Name: "a", Partitions: 2, ReplicationFactor: 3, Config: map[string]*string{"cleanup.policy": &policy}},
+		"b": {Name: "b", Partitions: 2, ReplicationFactor: 3, Config: map[string]*string{"retention.ms": &retention}},
+	}
+	declared := map[string]interface{}{
+		"a": `{"partitions":2,"replication_factor":3,"config":{"cleanup.policy":"delete, compact"}}`,
+		"b": `{"partitions":1,"replication_factor":3,"config":{"retention.ms":"100000"}}`,
+	}
+
+	expected := map[string]interface{}{
+		"a": declared["a"],
+		"b": `{"partitions":2,"replication_factor":3,"config":{"retention.ms":"100000"}}`,
+	}
+
+	got, err := flattenTopics(actual, declared)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Got %v, expected %v", got, expected)
+	}
+}
+
+func Test_DestroyedTopicNames(t *testing.T) {
+	failed := map[string]error{"b": fmt.Errorf("purging its records: %w", sarama.ErrRequestTimedOut)}
+
+	if destroyed := destroyedTopicNames([]string{"a", "b"}, "delete", nil); len(destroyed) != 0 {
+		t.Errorf("Expected deleted topics to be left to the read, got %v", destroyed)
+	}
+	if destroyed := destroyedTopicNames([]string{"a", "b"}, "purge", failed); !reflect.DeepEqual(destroyed, []string{"a"}) {
+		t.Errorf("Got %v, expected [a]", destroyed)
+	}
+	if destroyed := destroyedTopicNames([]string{"a", "b"}, "abandon", nil); !reflect.DeepEqual(destroyed, []string{"a", "b"}) {
+		t.Errorf("Got %v, expected [a b]", destroyed)
+	}
+}
+
+func topicListNames(topics []Topic) []string {
+	names := []string{}
+	for _, t := range topics {
+		names = append(names, t.Name)
+	}
+	return names
+}
+
+const testResourceTopics_initial = `
+resource "kafka_topics" "test" {
+  topics = {
+    "%s-a" = jsonencode({
+      partitions         = 1
+      replication_factor = 1
+      config = {
+        "retention.ms" = "100000"
+      }
+    })
+    "%s-b" = jsonencode({
+      partitions         = 1
+      replication_factor = 1
+    })
+  }
+}
+`
+
+const testResourceTopics_updated = `
+resource "kafka_topics" "test" {
+  topics = {
+    "%s-a" = jsonencode({
+      partitions         = 2
+      replication_factor = 1
+      config = {
+        "retention.ms" = "200000"
+      }
+    })
+    "%s-c" = jsonencode({
+      partitions         = 1
+      replication_factor = 1
+    })
+  }
+}
+`
@@ -3,16 +3,119 @@ package kafka
 import (
 	"errors"
 	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
 
-	"github.com/Shopify/sarama"
+	"github.com/IBM/sarama"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 )
 
 type Topic struct {
-	Name              string
+	Name string
+	// ID is only set by brokers >= 2.8.0
+	ID                string
 	Partitions        int32
 	ReplicationFactor int16
 	Config            map[string]*string
+	EffectiveConfig   []TopicConfigEntry
+	// PartitionReplicationFactors is only set when the partitions don't all
+	// have the same number of replicas, e.g. after a partial reassignment
+	PartitionReplicationFactors map[int32]int16
+}
+
+// TopicConfigEntry is the value of a config that applies to a topic, and
+// where it comes from
+type TopicConfigEntry struct {
+	Name      string
+	Value     string
+	Source    string
+	ReadOnly  bool
+	Sensitive bool
+}
+
+func newTopicConfigEntry(tc *sarama.ConfigEntry, version int) TopicConfigEntry {
+	source := tc.Source
+	if version == 0 && !tc.Default {
+		// v0 responses only tell defaults apart from topic overrides
+		source = sarama.SourceTopic
+	}
+
+	return TopicConfigEntry{
+		Name:      tc.Name,
+		Value:     tc.Value,
+		Source:    configSourceToString(source),
+		ReadOnly:  tc.ReadOnly,
+		Sensitive: tc.Sensitive,
+	}
+}
+
+func configSourceToString(s sarama.ConfigSource) string {
+	switch s {
+	case sarama.SourceTopic:
+		return "topic"
+	case sarama.SourceDynamicBroker:
+		return "dynamic_broker"
+	case sarama.SourceDynamicDefaultBroker:
+		return "dynamic_default_broker"
+	case sarama.SourceStaticBroker:
+		return "static_broker"
+	case sarama.SourceDefault:
+		return "default"
+	}
+	return "unknown"
+}
+
+// flattenEffectiveConfig converts the effective config of a topic to the
+// representation used in the schema
+func flattenEffectiveConfig(entries []TopicConfigEntry) []interface{} {
+	flattened := make([]interface{}, 0, len(entries))
+	for _, e := range entries {
+		flattened = append(flattened, map[string]interface{}{
+			"name":      e.Name,
+			"value":     e.Value,
+			"source":    e.Source,
+			"read_only": e.ReadOnly,
+			"sensitive": e.Sensitive,
+		})
+	}
+	return flattened
+}
+
+// effectiveConfigSchema describes the computed effective_config attribute
+func effectiveConfigSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:        schema.TypeList,
+		Computed:    true,
+		Description: "Every config that applies to the topic, including defaults, and where its value comes from.",
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"name": {
+					Type:     schema.TypeString,
+					Computed: true,
+				},
+				"value": {
+					Type:     schema.TypeString,
+					Computed: true,
+				},
+				"source": {
+					Type:     schema.TypeString,
+					Computed: true,
+				},
+				"read_only": {
+					Type:     schema.TypeBool,
+					Computed: true,
+				},
+				"sensitive": {
+					Type:     schema.TypeBool,
+					Computed: true,
+				},
+			},
+		},
+	}
 }
 
 func (t *Topic) Equal(other Topic) bool {
@@ -24,26 +127,123 @@ func (t *Topic) Equal(other Topic) bool {
 	return false
 }
 
-// ReplicaCount returns the replication_factor for a partition
-// Returns an error if it cannot determine the count, or if the number of
-// replicas is different across partitions
-func ReplicaCount(c sarama.Client, topic string, partitions []int32) (int, error) {
-	count := -1
+// ReplicaCounts returns the number of replicas of each partition
+// Returns an error if it cannot determine the count
+func ReplicaCounts(c sarama.Client, topic string, partitions []int32) (map[int32]int16, error) {
+	counts := make(map[int32]int16, len(partitions))
 
 	for _, p := range partitions {
 		replicas, err := c.Replicas(topic, p)
 		if err != nil {
-			return -1, errors.New("Could not get replicas for partition")
+			return counts, errors.New("Could not get replicas for partition")
+		}
+		counts[p] = int16(len(replicas))
+	}
+	return counts, nil
+}
+
+// majorityReplicationFactor returns the most common replica count across
+// partitions, preferring the lowest one on ties, and whether every partition
+// has that many replicas.
+func majorityReplicationFactor(counts map[int32]int16) (int16, bool) {
+	occurrences := map[int16]int{}
+	for _, c := range counts {
+		occurrences[c]++
+	}
+
+	rf := int16(-1)
+	for c, n := range occurrences {
+		if rf == -1 || n > occurrences[rf] || (n == occurrences[rf] && c < rf) {
+			rf = c
 		}
-		if count == -1 {
-			count = len(replicas)
+	}
+
+	return rf, len(occurrences) <= 1
+}
+
+// flattenReplicationFactors converts per-partition replica counts to the
+// representation used in the schema
+func flattenReplicationFactors(counts map[int32]int16) map[string]interface{} {
+	flattened := make(map[string]interface{}, len(counts))
+	for p, c := range counts {
+		flattened[strconv.Itoa(int(p))] = int(c)
+	}
+	return flattened
+}
+
+// topicNameMaxLength is the longest topic name Kafka accepts
+const topicNameMaxLength = 249
+
+var topicNameRegexp = regexp.MustCompile(`^[a-zA-Z0-9._-]+$`)
+
+var validateTopicName = validation.All(
+	validation.StringLenBetween(1, topicNameMaxLength),
+	validation.StringMatch(topicNameRegexp, "topic names may only contain ASCII alphanumerics, '.', '_' and '-'"),
+	validation.StringNotInSlice([]string{".", ".."}, false),
+)
+
+const (
+	topicImportPrefix = "prefix:"
+	topicImportRegex  = "regex:"
+)
+
+// matchTopics returns the topics matching an import ID, which is either
+// "prefix:<prefix>" or "regex:<regular expression>"
+func matchTopics(id string, topics []string) ([]string, error) {
+	var match func(string) bool
+	switch {
+	case strings.HasPrefix(id, topicImportPrefix):
+		prefix := strings.TrimPrefix(id, topicImportPrefix)
+		match = func(name string) bool { return strings.HasPrefix(name, prefix) }
+	case strings.HasPrefix(id, topicImportRegex):
+		re, err := regexp.Compile(strings.TrimPrefix(id, topicImportRegex))
+		if err != nil {
+			return nil, fmt.Errorf("invalid regex in '%s': %s", id, err)
 		}
-		if count != len(replicas) {
-			return count, fmt.Errorf("The replica count isn't the same across partitions %d != %d", count, len(replicas))
+		match = re.MatchString
+	default:
+		return nil, fmt.Errorf("Failed importing topics; expected an ID like %s<prefix> or %s<regex>, got '%s'", topicImportPrefix, topicImportRegex, id)
+	}
+
+	matched := []string{}
+	for _, name := range topics {
+		if match(name) {
+			matched = append(matched, name)
 		}
 	}
-	return count, nil
+	sort.Strings(matched)
 
+	if len(matched) == 0 {
+		return nil, fmt.Errorf("no topics match '%s'", id)
+	}
+	return matched, nil
+}
+
+// topicNameCollision checks a new topic name against the existing topics,
+// returning an error if one of them only differs by case, or by using "."
+// where the other uses "_" (which collide in Kafka's metric names).
+func topicNameCollision(name string, existing []string) error {
+	// sort a copy so the error names the same topic every run, without
+	// reordering the caller's slice
+	existing = append([]string{}, existing...)
+	sort.Strings(existing)
+	metricName := strings.ReplaceAll(name, ".", "_")
+
+	for _, other := range existing {
+		if other == name {
+			continue
+		}
+
+		if strings.ReplaceAll(other, ".", "_") == metricName {
+			return fmt.Errorf("topic name '%s' collides with existing topic '%s': '.' and '_' are interchangeable in Kafka's metric names", name, other)
+		}
+
+		if strings.EqualFold(other, name) {
+			return fmt.Errorf("topic name '%s' only differs in case from existing topic '%s', which collides on case-insensitive filesystems", name, other)
+		}
+	}
+
+	return nil
 }
 
 func configToResources(topic Topic) []*sarama.AlterConfigsResource {
@@ -56,6 +256,113 @@ func configToResources(topic Topic) []*sarama.AlterConfigsResource {
 	}
 }
 
+// configChanges returns the configs to set and the ones to remove to go from
+// the old config to the new one
+func configChanges(old, new map[string]interface{}) (map[string]*string, []string) {
+	set := map[string]*string{}
+	for k, v := range new {
+		s, ok := v.(string)
+		if !ok {
+			continue
+		}
+		if o, ok := old[k].(string); !ok || o != s {
+			set[k] = &s
+		}
+	}
+
+	remove := []string{}
+	for k := range old {
+		if _, ok := new[k]; !ok {
+			remove = append(remove, k)
+		}
+	}
+	sort.Strings(remove)
+
+	return set, remove
+}
+
+// configAfterChanges returns the topic-level overrides of a topic once the
+// changes are applied
+func configAfterChanges(effective []TopicConfigEntry, set map[string]*string, remove []string) map[string]*string {
+	config := map[string]*string{}
+	for _, e := range effective {
+		if e.Source == "topic" {
+			v := e.Value
+			config[e.Name] = &v
+		}
+	}
+
+	for _, k := range remove {
+		delete(config, k)
+	}
+	for k, v := range set {
+		config[k] = v
+	}
+
+	return config
+}
+
+// isIgnoredConfigKey is true when the key matches one of the glob patterns
+func isIgnoredConfigKey(key string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if ok, err := path.Match(pattern, key); err == nil && ok {
+			return true
+		}
+	}
+	return false
+}
+
+// withoutIgnoredConfig returns the config without the keys matching the
+// patterns
+func withoutIgnoredConfig(config map[string]*string, patterns []string) map[string]*string {
+	if len(patterns) == 0 {
+		return config
+	}
+
+	filtered := make(map[string]*string, len(config))
+	for k, v := range config {
+		if !isIgnoredConfigKey(k, patterns) {
+			filtered[k] = v
+		}
+	}
+	return filtered
+}
+
+func validateConfigKeyPattern(i interface{}, k string) (warnings []string, errors []error) {
+	pattern, ok := i.(string)
+	if !ok {
+		return nil, []error{fmt.Errorf("expected type of %s to be a string", k)}
+	}
+
+	if _, err := path.Match(pattern, ""); err != nil {
+		return nil, []error{fmt.Errorf("%s: invalid pattern '%s': %s", k, pattern, err)}
+	}
+	return nil, nil
+}
+
+// pinDeclaredConfig only keeps the declared keys in the config when the topic
+// has an override for them, whatever the brokers consider a default. Declared
+// keys that are inherited from the brokers are left out, so that the next plan
+// sets them on the topic again.
+func pinDeclaredConfig(config map[string]*string, effective []TopicConfigEntry, declared map[string]interface{}) map[string]*string {
+	pinned := map[string]*string{}
+	for k, v := range config {
+		if _, ok := declared[k]; !ok {
+			pinned[k] = v
+		}
+	}
+
+	for _, e := range effective {
+		if _, ok := declared[e.Name]; !ok || e.Source != "topic" {
+			continue
+		}
+		v := e.Value
+		pinned[e.Name] = &v
+	}
+
+	return pinned
+}
+
 func isDefault(tc *sarama.ConfigEntry, version int) bool {
 	if version == 0 {
 		return tc.Default
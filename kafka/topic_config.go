@@ -0,0 +1,331 @@
+package kafka
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+type topicConfigType int
+
+const (
+	configString topicConfigType = iota
+	configBoolean
+	configInt
+	configLong
+	configDouble
+	configList
+)
+
+func (t topicConfigType) String() string {
+	switch t {
+	case configBoolean:
+		return "boolean"
+	case configInt:
+		return "int"
+	case configLong:
+		return "long"
+	case configDouble:
+		return "double"
+	case configList:
+		return "list"
+	}
+	return "string"
+}
+
+type configRange struct {
+	min float64
+	max float64
+}
+
+func atLeast(min float64) *configRange {
+	return &configRange{min: min, max: math.Inf(1)}
+}
+
+func between(min, max float64) *configRange {
+	return &configRange{min: min, max: max}
+}
+
+// topicConfigSpec describes a topic-level config supported by the brokers
+type topicConfigSpec struct {
+	Type        topicConfigType
+	Range       *configRange
+	ValidValues []string
+	// DeprecatedSince is the Kafka version the config was deprecated in
+	DeprecatedSince string
+	Replacement     string
+}
+
+// topicConfigCatalog lists the topic-level configs documented at
+// https://kafka.apache.org/documentation/#topicconfigs
+var topicConfigCatalog = map[string]topicConfigSpec{
+	"cleanup.policy":       {Type: configList, ValidValues: []string{"compact", "delete"}},
+	"compression.type":     {Type: configString, ValidValues: []string{"uncompressed", "zstd", "lz4", "snappy", "gzip", "producer"}},
+	"delete.retention.ms":  {Type: configLong, Range: atLeast(0)},
+	"file.delete.delay.ms": {Type: configLong, Range: atLeast(0)},
+	"flush.messages":       {Type: configLong, Range: atLeast(1)},
+	"flush.ms":             {Type: configLong, Range: atLeast(0)},
+	"follower.replication.throttled.replicas": {Type: configList},
+	"index.interval.bytes":                    {Type: configInt, Range: atLeast(0)},
+	"leader.replication.throttled.replicas":   {Type: configList},
+	"local.retention.bytes":                   {Type: configLong, Range: atLeast(-2)},
+	"local.retention.ms":                      {Type: configLong, Range: atLeast(-2)},
+	"max.compaction.lag.ms":                   {Type: configLong, Range: atLeast(1)},
+	"max.message.bytes":                       {Type: configInt, Range: atLeast(0)},
+	"message.downconversion.enable":           {Type: configBoolean},
+	"message.format.version":                  {Type: configString, DeprecatedSince: "3.0.0"},
+	"message.timestamp.after.max.ms":          {Type: configLong, Range: atLeast(0)},
+	"message.timestamp.before.max.ms":         {Type: configLong, Range: atLeast(0)},
+	"message.timestamp.difference.max.ms": {
+		Type:            configLong,
+		Range:           atLeast(0),
+		DeprecatedSince: "3.6.0",
+		Replacement:     "message.timestamp.before.max.ms and message.timestamp.after.max.ms",
+	},
+	"message.timestamp.type":         {Type: configString, ValidValues: []string{"CreateTime", "LogAppendTime"}},
+	"min.cleanable.dirty.ratio":      {Type: configDouble, Range: between(0, 1)},
+	"min.compaction.lag.ms":          {Type: configLong, Range: atLeast(0)},
+	"min.insync.replicas":            {Type: configInt, Range: atLeast(1)},
+	"preallocate":                    {Type: configBoolean},
+	"remote.storage.enable":          {Type: configBoolean},
+	"retention.bytes":                {Type: configLong},
+	"retention.ms":                   {Type: configLong, Range: atLeast(-1)},
+	"segment.bytes":                  {Type: configInt, Range: atLeast(14)},
+	"segment.index.bytes":            {Type: configInt, Range: atLeast(4)},
+	"segment.jitter.ms":              {Type: configLong, Range: atLeast(0)},
+	"segment.ms":                     {Type: configLong, Range: atLeast(1)},
+	"unclean.leader.election.enable": {Type: configBoolean},
+}
+
+// validateTopicConfig checks a config against the catalog. Unknown keys only
+// produce a warning, suggesting the closest known key when they look like a
+// misspelling of it, since brokers newer than the catalog (or vendor
+// distributions) support more configs. Deprecated keys produce a warning too,
+// naming the Kafka version that deprecated them: validation happens before the
+// version of the brokers is known.
+func validateTopicConfig(key, value string) (warning string, err error) {
+	spec, ok := topicConfigCatalog[key]
+	if !ok {
+		if suggestion := closestTopicConfig(key); suggestion != "" {
+			return fmt.Sprintf("'%s' is not a known topic config, did you mean '%s'? It won't be validated", key, suggestion), nil
+		}
+		return fmt.Sprintf("'%s' is not a known topic config, it won't be validated", key), nil
+	}
+
+	switch spec.Type {
+	case configBoolean:
+		// unlike strconv.ParseBool, the brokers don't accept "1" or "t"
+		if !isConfigBoolean(value) {
+			return "", fmt.Errorf("%s must be true or false, got '%s'", key, value)
+		}
+	case configInt, configLong:
+		bitSize := 64
+		if spec.Type == configInt {
+			bitSize = 32
+		}
+		n, err := strconv.ParseInt(strings.TrimSpace(value), 10, bitSize)
+		if err != nil {
+			return "", fmt.Errorf("%s must be a %s, got '%s'", key, spec.Type, value)
+		}
+		if err := spec.checkRange(key, float64(n)); err != nil {
+			return "", err
+		}
+	case configDouble:
+		f, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil {
+			return "", fmt.Errorf("%s must be a double, got '%s'", key, value)
+		}
+		if err := spec.checkRange(key, f); err != nil {
+			return "", err
+		}
+	case configList:
+		for _, item := range splitConfigList(value) {
+			if err := spec.checkValidValue(key, item); err != nil {
+				return "", err
+			}
+		}
+	default:
+		if err := spec.checkValidValue(key, value); err != nil {
+			return "", err
+		}
+	}
+
+	return spec.deprecation(key), nil
+}
+
+func isConfigBoolean(value string) bool {
+	return strings.EqualFold(value, "true") || strings.EqualFold(value, "false")
+}
+
+// deprecation describes the deprecation of the config, if it is deprecated
+func (spec topicConfigSpec) deprecation(key string) string {
+	if spec.DeprecatedSince == "" {
+		return ""
+	}
+
+	msg := fmt.Sprintf("%s is deprecated since Kafka %s", key, spec.DeprecatedSince)
+	if spec.Replacement != "" {
+		msg = fmt.Sprintf("%s, use %s instead", msg, spec.Replacement)
+	}
+	return msg
+}
+
+func (spec topicConfigSpec) checkRange(key string, v float64) error {
+	if spec.Range == nil {
+		return nil
+	}
+	if v < spec.Range.min || v > spec.Range.max {
+		if math.IsInf(spec.Range.max, 1) {
+			return fmt.Errorf("%s must be at least %v, got %v", key, spec.Range.min, v)
+		}
+		return fmt.Errorf("%s must be between %v and %v, got %v", key, spec.Range.min, spec.Range.max, v)
+	}
+	return nil
+}
+
+func (spec topicConfigSpec) checkValidValue(key, v string) error {
+	if len(spec.ValidValues) == 0 {
+		return nil
+	}
+	for _, valid := range spec.ValidValues {
+		if v == valid {
+			return nil
+		}
+	}
+	return fmt.Errorf("%s must be one of %s, got '%s'", key, strings.Join(spec.ValidValues, ", "), v)
+}
+
+// closestTopicConfig returns the known config key closest to key, if it is
+// close enough to be a typo.
+func closestTopicConfig(key string) string {
+	best := ""
+	bestDistance := 3
+	for known := range topicConfigCatalog {
+		if d := levenshtein(key, known); d < bestDistance || (d == bestDistance && known < best) {
+			best = known
+			bestDistance = d
+		}
+	}
+	if bestDistance > 2 {
+		return ""
+	}
+	return best
+}
+
+func levenshtein(a, b string) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = minInt(minInt(prev[j]+1, curr[j-1]+1), prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+
+	return prev[len(b)]
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func splitConfigList(value string) []string {
+	items := []string{}
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// unknownVariableValue is how the SDK represents config values that won't be
+// known until apply
+const unknownVariableValue = "74D93920-ED26-11E3-AC10-0800200C9A66"
+
+func validateTopicConfigMap(i interface{}, k string) (warnings []string, errors []error) {
+	config, ok := i.(map[string]interface{})
+	if !ok {
+		return nil, []error{fmt.Errorf("expected type of %s to be a map", k)}
+	}
+
+	keys := make([]string, 0, len(config))
+	for key := range config {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		value, ok := config[key].(string)
+		if !ok || value == unknownVariableValue {
+			continue
+		}
+
+		warning, err := validateTopicConfig(key, value)
+		if warning != "" {
+			warnings = append(warnings, warning)
+		}
+		if err != nil {
+			errors = append(errors, err)
+		}
+	}
+
+	return warnings, errors
+}
+
+// suppressEquivalentTopicConfig ignores differences between equivalent forms
+// of the same config value.
+func suppressEquivalentTopicConfig(k, old, new string, d *schema.ResourceData) bool {
+	key := strings.TrimPrefix(k, "config.")
+	if key == "%" {
+		return false
+	}
+	return normalizeTopicConfig(key, old) == normalizeTopicConfig(key, new)
+}
+
+// normalizeTopicConfig returns the canonical form of a config value, so that
+// equivalent values, like "compact,delete" and "delete, compact", compare
+// equal.
+func normalizeTopicConfig(key, value string) string {
+	spec, ok := topicConfigCatalog[key]
+	if !ok {
+		return value
+	}
+
+	switch spec.Type {
+	case configBoolean:
+		if isConfigBoolean(value) {
+			return strings.ToLower(value)
+		}
+	case configInt, configLong:
+		if n, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64); err == nil {
+			return strconv.FormatInt(n, 10)
+		}
+	case configDouble:
+		if f, err := strconv.ParseFloat(strings.TrimSpace(value), 64); err == nil {
+			return strconv.FormatFloat(f, 'g', -1, 64)
+		}
+	case configList:
+		items := splitConfigList(value)
+		sort.Strings(items)
+		return strings.Join(items, ",")
+	}
+
+	return value
+}
@@ -0,0 +1,78 @@
+package kafka
+
+import (
+	"strings"
+	"testing"
+)
+
+func Test_ValidateTopicConfig(t *testing.T) {
+	valid := map[string]string{
+		"retention.ms":                   "604800000",
+		"retention.bytes":                "-1",
+		"cleanup.policy":                 "compact, delete",
+		"unclean.leader.election.enable": "True",
+		"min.cleanable.dirty.ratio":      "0.5",
+		"compression.type":               "zstd",
+	}
+	for k, v := range valid {
+		if warning, err := validateTopicConfig(k, v); err != nil || warning != "" {
+			t.Errorf("expected %s = %s to be valid, got %v %s", k, v, err, warning)
+		}
+	}
+
+	invalid := map[string]string{
+		"retention.ms":              "7d",
+		"segment.ms":                "0",
+		"segment.bytes":             "4294967296",
+		"cleanup.policy":            "compact,remove",
+		"min.cleanable.dirty.ratio": "1.5",
+		"compression.type":          "brotli",
+		"preallocate":               "1",
+		"remote.storage.enable":     "t",
+	}
+	for k, v := range invalid {
+		if _, err := validateTopicConfig(k, v); err == nil {
+			t.Errorf("expected %s = %s to be invalid", k, v)
+		}
+	}
+
+	warning, err := validateTopicConfig("confluent.value.schema.validation", "true")
+	if err != nil || warning == "" {
+		t.Errorf("expected a warning for an unknown config, got %v '%s'", err, warning)
+	}
+
+	// a config the catalog doesn't know yet may look like a misspelling
+	warning, err = validateTopicConfig("retention.mss", "1000")
+	if err != nil || !strings.Contains(warning, "did you mean 'retention.ms'?") {
+		t.Errorf("expected a warning suggesting retention.ms, got %v '%s'", err, warning)
+	}
+
+	warning, err = validateTopicConfig("message.timestamp.difference.max.ms", "1000")
+	expected := "message.timestamp.difference.max.ms is deprecated since Kafka 3.6.0, use message.timestamp.before.max.ms and message.timestamp.after.max.ms instead"
+	if err != nil || warning != expected {
+		t.Errorf("expected a deprecation warning, got %v '%s'", err, warning)
+	}
+}
+
+func Test_NormalizeTopicConfig(t *testing.T) {
+	cases := []struct {
+		key      string
+		values   []string
+		expected string
+	}{
+		{"cleanup.policy", []string{"delete,compact", "compact, delete", " compact,delete "}, "compact,delete"},
+		{"preallocate", []string{"TRUE", "true", "True"}, "true"},
+		{"preallocate", []string{"1"}, "1"},
+		{"retention.ms", []string{"1000", "01000", " 1000"}, "1000"},
+		{"min.cleanable.dirty.ratio", []string{"0.5", "0.50", ".5"}, "0.5"},
+		{"some.unknown.config", []string{"Foo"}, "Foo"},
+	}
+
+	for _, c := range cases {
+		for _, v := range c.values {
+			if got := normalizeTopicConfig(c.key, v); got != c.expected {
+				t.Errorf("%s: %s normalized to %s, expected %s", c.key, v, got, c.expected)
+			}
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package kafka
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+
+	"github.com/IBM/sarama"
+)
+
+// https://kafka.apache.org/protocol#The_Messages_Metadata
+const apiKeyMetadata = 3
+
+// Metadata responses include topic IDs from version 10 on, which sarama only
+// sends to brokers it's configured to know are at least at Kafka 2.8.0
+const metadataTopicIDsVersion = 10
+
+// CanReadTopicIDs is true when the brokers assign IDs to topics
+func (c *Client) CanReadTopicIDs() bool {
+	return c.versionForKey(apiKeyMetadata, metadataTopicIDsVersion) >= metadataTopicIDsVersion
+}
+
+// TopicIDs returns the IDs of the topics, keyed by name. Topics that don't
+// exist are left out of the result. Topics are read often, so the connection
+// is kept open for the next reads.
+func (c *Client) TopicIDs(names []string) (map[string]string, error) {
+	if !c.CanReadTopicIDs() {
+		return nil, errors.New("Need kafka >= 2.8.0 to read topic IDs")
+	}
+
+	controller, err := c.client.Controller()
+	if err != nil {
+		return nil, err
+	}
+
+	dial := func() (*sarama.Broker, error) {
+		return c.dialBroker(controller.Addr(), func(config *sarama.Config) {
+			config.Version = sarama.V2_8_0_0
+		})
+	}
+
+	log.Printf("[DEBUG] Reading the IDs of %d topics", len(names))
+	var res *sarama.MetadataResponse
+	err = c.topicIDConns.do(controller.Addr(), dial, func(conn *sarama.Broker) (err error) {
+		res, err = conn.GetMetadata(&sarama.MetadataRequest{
+			Version: metadataTopicIDsVersion,
+			Topics:  names,
+		})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return metadataTopicIDs(res)
+}
+
+func metadataTopicIDs(res *sarama.MetadataResponse) (map[string]string, error) {
+	ids := map[string]string{}
+	failed := []string{}
+	for _, t := range res.Topics {
+		switch t.Err {
+		case sarama.ErrNoError:
+			ids[t.Name] = formatTopicID(t.Uuid)
+		case sarama.ErrUnknownTopicOrPartition:
+			log.Printf("[DEBUG] Topic %s doesn't exist", t.Name)
+		default:
+			failed = append(failed, fmt.Sprintf("%s: %s", t.Name, t.Err))
+		}
+	}
+
+	if len(failed) > 0 {
+		return ids, fmt.Errorf("could not read the topic IDs: %v", failed)
+	}
+
+	return ids, nil
+}
+
+// brokerConnCache keeps a connection of its own to a broker open between
+// requests, for requests the connections of the sarama client can't send
+type brokerConnCache struct {
+	mu   sync.Mutex
+	conn *sarama.Broker
+}
+
+// do runs f with the connection to the broker at addr, dialing it first if
+// there is none yet. The broker may have closed a connection left idle since
+// the last request, so when f fails on it, f runs again once on a new
+// connection. The connection is closed when f fails, so that the next request
+// dials a new one.
+func (cache *brokerConnCache) do(addr string, dial func() (*sarama.Broker, error), f func(*sarama.Broker) error) error {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	if cache.conn != nil && cache.conn.Addr() != addr {
+		cache.closeLocked()
+	}
+
+	reused := cache.conn != nil
+	for {
+		if cache.conn == nil {
+			conn, err := dial()
+			if err != nil {
+				return err
+			}
+			cache.conn = conn
+		}
+
+		err := f(cache.conn)
+		if err == nil {
+			return nil
+		}
+
+		cache.closeLocked()
+		if !reused {
+			return err
+		}
+		log.Printf("[DEBUG] Request to %s failed on a reused connection, retrying on a new one: %s", addr, err)
+		reused = false
+	}
+}
+
+func (cache *brokerConnCache) closeLocked() {
+	if err := cache.conn.Close(); err != nil && err != sarama.ErrNotConnected {
+		log.Printf("[WARN] Error closing connection to %s: %s", cache.conn.Addr(), err)
+	}
+	cache.conn = nil
+}
+
+// formatTopicID formats the ID the way Kafka's tools do, or returns an empty
+// string for the zero ID of topics created before Kafka 2.8.0
+func formatTopicID(id [16]byte) string {
+	if id == [16]byte{} {
+		return ""
+	}
+	return base64.RawURLEncoding.EncodeToString(id[:])
+}
+
+// configForReplacedTopic returns the config of a topic that was deleted and
+// recreated outside of Terraform, without the declared values it happens to
+// have, so that every declared config is applied to it again
+func configForReplacedTopic(actual map[string]*string, declared map[string]interface{}) map[string]*string {
+	config := make(map[string]*string, len(actual))
+	for k, v := range actual {
+		if d, ok := declared[k]; ok && v != nil && d == *v {
+			continue
+		}
+		config[k] = v
+	}
+	return config
+}
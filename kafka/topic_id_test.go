@@ -0,0 +1,145 @@
+package kafka
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/IBM/sarama"
+)
+
+func Test_MetadataTopicIDs(t *testing.T) {
+	id := [16]byte{0xbc, 0x8d, 0xee, 0x03, 0xcf, 0xb0, 0x41, 0x55, 0x94, 0x42, 0x3c, 0xd6, 0x9e, 0x50, 0x3d, 0x52}
+	res := &sarama.MetadataResponse{
+		Topics: []*sarama.TopicMetadata{
+			{Name: "foo", Uuid: id},
+			{Name: "bar", Err: sarama.ErrUnknownTopicOrPartition},
+		},
+	}
+
+	ids, err := metadataTopicIDs(res)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]string{"foo": "vI3uA8-wQVWUQjzWnlA9Ug"}
+	if !reflect.DeepEqual(ids, expected) {
+		t.Errorf("%v != %v", ids, expected)
+	}
+
+	res.Topics = append(res.Topics, &sarama.TopicMetadata{Name: "baz", Err: sarama.ErrTopicAuthorizationFailed})
+	if _, err := metadataTopicIDs(res); err == nil {
+		t.Errorf("expected an error for a topic whose ID can't be read")
+	}
+}
+
+func Test_FormatTopicID(t *testing.T) {
+	if got := formatTopicID([16]byte{}); got != "" {
+		t.Errorf("expected the zero ID to be empty, got %s", got)
+	}
+}
+
+func Test_TopicIDChange(t *testing.T) {
+	cases := []struct {
+		previous, read string
+		id             string
+		replaced       bool
+	}{
+		{"", "", "", false},
+		{"", "a", "a", false},
+		{"a", "a", "a", false},
+		{"a", "b", "b", true},
+		// the ID couldn't be read
+		{"a", "", "a", false},
+	}
+
+	for _, c := range cases {
+		id, replaced := topicIDChange(c.previous, c.read)
+		if id != c.id || replaced != c.replaced {
+			t.Errorf("%q -> %q: got (%q, %v), expected (%q, %v)", c.previous, c.read, id, replaced, c.id, c.replaced)
+		}
+	}
+}
+
+func Test_ConfigForReplacedTopic(t *testing.T) {
+	retention := "100000"
+	segment := "1000"
+	compact := "compact"
+
+	actual := map[string]*string{
+		"retention.ms":   &retention,
+		"segment.ms":     &segment,
+		"cleanup.policy": &compact,
+	}
+	declared := map[string]interface{}{
+		"retention.ms":   "100000",
+		"cleanup.policy": "delete",
+	}
+
+	expected := map[string]*string{
+		"segment.ms":     &segment,
+		"cleanup.policy": &compact,
+	}
+	if got := configForReplacedTopic(actual, declared); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Got %v, expected %v", strPtrMapToStrMap(got), strPtrMapToStrMap(expected))
+	}
+}
+
+func Test_BrokerConnCacheReusesConnection(t *testing.T) {
+	dials := 0
+	dial := func() (*sarama.Broker, error) {
+		dials++
+		return sarama.NewBroker("localhost:9092"), nil
+	}
+	request := func(*sarama.Broker) error { return nil }
+
+	cache := &brokerConnCache{}
+	for i := 0; i < 3; i++ {
+		if err := cache.do("localhost:9092", dial, request); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if dials != 1 {
+		t.Errorf("Expected one connection for 3 requests, got %d", dials)
+	}
+
+	// a request failing on a reused connection is retried once on a new one
+	failure := errors.New("failed")
+	if err := cache.do("localhost:9092", dial, func(*sarama.Broker) error { return failure }); err != failure {
+		t.Errorf("Expected %v, got %v", failure, err)
+	}
+	if dials != 2 {
+		t.Errorf("Expected a retry on a new connection, got %d connections", dials)
+	}
+
+	// the failed connection is closed, and the next request opens another
+	if err := cache.do("localhost:9092", dial, request); err != nil {
+		t.Fatal(err)
+	}
+	if dials != 3 {
+		t.Errorf("Expected a new connection after a failed request, got %d connections", dials)
+	}
+
+	stale := true
+	staleOnce := func(*sarama.Broker) error {
+		if stale {
+			stale = false
+			return errors.New("broken pipe")
+		}
+		return nil
+	}
+	if err := cache.do("localhost:9092", dial, staleOnce); err != nil {
+		t.Errorf("Expected the retry on a new connection to succeed, got %v", err)
+	}
+	if dials != 4 {
+		t.Errorf("Expected one new connection for the retry, got %d connections", dials)
+	}
+
+	// a new controller gets a connection of its own
+	if err := cache.do("localhost:9093", dial, request); err != nil {
+		t.Fatal(err)
+	}
+	if dials != 5 {
+		t.Errorf("Expected a new connection to another broker, got %d connections", dials)
+	}
+}
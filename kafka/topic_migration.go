@@ -0,0 +1,607 @@
+package kafka
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/IBM/sarama"
+)
+
+// Kafka can't remove partitions from a topic, so reducing them means
+// recreating the topic. MigrateTopic does that without losing its records, by
+// copying them to a temporary topic and back.
+
+const (
+	migrationTopicSuffix = "-shrink-tmp"
+	migrationBatchSize   = 500
+	// how long to watch a topic for new records before migrating it
+	migrationQuietPeriod = 10 * time.Second
+	// how long ago the last record of a topic must have been produced for
+	// it to be migrated
+	migrationProducerWindow = 5 * time.Minute
+)
+
+// migrationIdleTimeout is how long to wait for more records before a copy of
+// a partition stops. A partition whose last offsets are transaction markers,
+// which are never consumed, then fails the verification of the copy.
+var migrationIdleTimeout = 5 * time.Second
+
+// MigrateTopic recreates the topic with the partitions, replication factor
+// and config of t, copying its records over. Records keep their keys, headers
+// and timestamps, and are assigned to partitions the way the default
+// partitioner of the Java clients would. It refuses to run while the topic
+// has active producers or consumer group members: records must not have been
+// produced to it within migrationProducerWindow, nor while it is watched and
+// copied. The committed offsets of consumer groups are lost, as the records
+// get new offsets.
+func (c *Client) MigrateTopic(t Topic, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	tmp := t.Name + migrationTopicSuffix
+	if len(tmp) > topicNameMaxLength {
+		return fmt.Errorf("topic name %s is too long to migrate it through a temporary topic", t.Name)
+	}
+
+	if err := c.client.RefreshMetadata(); err != nil {
+		return err
+	}
+	if err := c.extractTopics(); err != nil {
+		return err
+	}
+	if _, ok := c.topics[tmp]; ok {
+		return fmt.Errorf("temporary topic %s already exists, it may hold the records of a previous migration of %s", tmp, t.Name)
+	}
+
+	partitions, err := c.client.Partitions(t.Name)
+	if err != nil {
+		return err
+	}
+
+	if err := c.checkNoActiveConsumers(t.Name); err != nil {
+		return err
+	}
+
+	ends, err := c.endOffsets(t.Name, partitions)
+	if err != nil {
+		return err
+	}
+	if err := c.checkNoRecentRecords(t.Name, partitions, ends); err != nil {
+		return err
+	}
+	log.Printf("[INFO] Watching %s for new records for %s before migrating it", t.Name, migrationQuietPeriod)
+	time.Sleep(migrationQuietPeriod)
+	if err := c.checkNoNewRecords(t.Name, partitions, ends); err != nil {
+		return err
+	}
+
+	sourceConfig, _, err := c.topicConfig(t.Name)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("[INFO] Copying %s to %s", t.Name, tmp)
+	tmpTopic := Topic{
+		Name:              tmp,
+		Partitions:        int32(len(partitions)),
+		ReplicationFactor: t.ReplicationFactor,
+		Config:            migrationTopicConfig(sourceConfig),
+	}
+	if err := c.CreateTopic(tmpTopic, time.Until(deadline)); err != nil {
+		return err
+	}
+	if err := c.waitForTopic(tmp, true, deadline); err != nil {
+		return err
+	}
+
+	samePartition := func(msg *sarama.ConsumerMessage) int32 { return msg.Partition }
+	copies, err := c.copyRecords(t.Name, tmp, ends, samePartition, deadline)
+	if err != nil {
+		return c.abortMigration(t.Name, tmp, err, timeout)
+	}
+
+	// the source topic is only deleted once the watermarks of the temporary
+	// topic show that it holds every record
+	tmpCounts, err := c.recordCounts(tmp, partitions)
+	if err != nil {
+		return c.abortMigration(t.Name, tmp, err, timeout)
+	}
+	if err := verifyCopy(copies, tmpCounts); err != nil {
+		return c.abortMigration(t.Name, tmp, fmt.Errorf("copying %s to %s: %w", t.Name, tmp, err), timeout)
+	}
+	copied := totalRecords(tmpCounts)
+
+	// the topic may have been written to while it was being copied
+	if err := c.checkNoNewRecords(t.Name, partitions, ends); err != nil {
+		return c.abortMigration(t.Name, tmp, err, timeout)
+	}
+
+	log.Printf("[INFO] Recreating %s with %d partitions", t.Name, t.Partitions)
+	if err := c.DeleteTopic(t.Name, time.Until(deadline)); err != nil {
+		return c.abortMigration(t.Name, tmp, err, timeout)
+	}
+
+	// from here on, the records are only in the temporary topic, so it's
+	// kept when something goes wrong
+	if err := c.waitForTopic(t.Name, false, deadline); err != nil {
+		return migrationError(t.Name, tmp, err)
+	}
+	if err := c.CreateTopic(t, time.Until(deadline)); err != nil {
+		return migrationError(t.Name, tmp, err)
+	}
+	if err := c.waitForTopic(t.Name, true, deadline); err != nil {
+		return migrationError(t.Name, tmp, err)
+	}
+
+	tmpPartitions, err := c.client.Partitions(tmp)
+	if err != nil {
+		return migrationError(t.Name, tmp, err)
+	}
+	tmpEnds, err := c.endOffsets(tmp, tmpPartitions)
+	if err != nil {
+		return migrationError(t.Name, tmp, err)
+	}
+
+	byKey := func(msg *sarama.ConsumerMessage) int32 {
+		return partitionForKey(msg.Key, msg.Partition, t.Partitions)
+	}
+	copiesBack, err := c.copyRecords(tmp, t.Name, tmpEnds, byKey, deadline)
+	if err != nil {
+		return migrationError(t.Name, tmp, err)
+	}
+	// the temporary topic has neither gaps nor transaction markers, so every
+	// partition is copied up to its last offset. The records are spread over
+	// the partitions by key, so only the total is compared.
+	if err := verifyCopy(copiesBack, nil); err != nil {
+		return migrationError(t.Name, tmp, fmt.Errorf("copying %s back: %w", tmp, err))
+	}
+	newPartitions, err := c.client.Partitions(t.Name)
+	if err != nil {
+		return migrationError(t.Name, tmp, err)
+	}
+	newCounts, err := c.recordCounts(t.Name, newPartitions)
+	if err != nil {
+		return migrationError(t.Name, tmp, err)
+	}
+	if copiedBack := totalRecords(newCounts); copiedBack != copied {
+		return migrationError(t.Name, tmp, fmt.Errorf("copied %d records to the temporary topic but %d back", copied, copiedBack))
+	}
+
+	log.Printf("[INFO] Migrated %d records of %s, deleting %s", copied, t.Name, tmp)
+	return c.DeleteTopic(tmp, time.Until(deadline))
+}
+
+// abortMigration deletes the temporary topic of a migration that failed
+// before the topic was deleted. The migration's deadline may have passed
+// already, so the deletion gets the migration's whole timeout.
+func (c *Client) abortMigration(topic, tmp string, err error, timeout time.Duration) error {
+	log.Printf("[WARN] Aborting the migration of %s: %s", topic, err)
+	if derr := c.DeleteTopic(tmp, timeout); derr != nil {
+		return fmt.Errorf("%s; deleting the temporary topic %s also failed: %s", err, tmp, derr)
+	}
+	return fmt.Errorf("migration of %s aborted, the topic is unchanged: %w", topic, err)
+}
+
+func migrationError(topic, tmp string, err error) error {
+	return fmt.Errorf("migration of %s failed after deleting it, its records are kept in %s: %w", topic, tmp, err)
+}
+
+// checkNoActiveConsumers returns an error when a member of a consumer group
+// is subscribed to, or assigned partitions of, the topic
+func (c *Client) checkNoActiveConsumers(topic string) error {
+	admin, err := sarama.NewClusterAdminFromClient(c.client)
+	if err != nil {
+		return err
+	}
+
+	groups, err := admin.ListConsumerGroups()
+	if err != nil {
+		return err
+	}
+	if len(groups) == 0 {
+		return nil
+	}
+
+	ids := make([]string, 0, len(groups))
+	for id := range groups {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	descriptions, err := admin.DescribeConsumerGroups(ids)
+	if err != nil {
+		return err
+	}
+
+	active := []string{}
+	for _, g := range descriptions {
+		if g.ProtocolType != "consumer" {
+			continue
+		}
+		for _, m := range g.Members {
+			if memberUsesTopic(m, topic) {
+				active = append(active, g.GroupId)
+				break
+			}
+		}
+	}
+
+	if len(active) > 0 {
+		return fmt.Errorf("topic %s has active consumers in groups %v", topic, active)
+	}
+	return nil
+}
+
+func memberUsesTopic(m *sarama.GroupMemberDescription, topic string) bool {
+	if assignment, err := m.GetMemberAssignment(); err == nil {
+		if _, ok := assignment.Topics[topic]; ok {
+			return true
+		}
+	}
+
+	if metadata, err := m.GetMemberMetadata(); err == nil {
+		for _, t := range metadata.Topics {
+			if t == topic {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// migrationTopicConfig returns the config of the temporary topic of a
+// migration: the one of the source topic, so that the brokers accept the same
+// records, without retention or compaction so that none are removed while
+// they are only in the temporary topic.
+func migrationTopicConfig(source map[string]*string) map[string]*string {
+	config := map[string]*string{}
+	for k, v := range source {
+		switch {
+		case k == "cleanup.policy", strings.HasPrefix(k, "retention."), strings.HasSuffix(k, ".replication.throttled.replicas"):
+			continue
+		}
+		config[k] = v
+	}
+
+	retention := "-1"
+	cleanup := "delete"
+	config["retention.ms"] = &retention
+	config["retention.bytes"] = &retention
+	config["cleanup.policy"] = &cleanup
+	return config
+}
+
+// recordCounts returns the number of offsets of each partition of the topic
+func (c *Client) recordCounts(topic string, partitions []int32) (map[int32]int64, error) {
+	ends, err := c.endOffsets(topic, partitions)
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[int32]int64, len(partitions))
+	for _, p := range partitions {
+		oldest, err := c.client.GetOffset(topic, p, sarama.OffsetOldest)
+		if err != nil {
+			return nil, err
+		}
+		counts[p] = ends[p] - oldest
+	}
+	return counts, nil
+}
+
+func (c *Client) endOffsets(topic string, partitions []int32) (map[int32]int64, error) {
+	ends := make(map[int32]int64, len(partitions))
+	for _, p := range partitions {
+		offset, err := c.client.GetOffset(topic, p, sarama.OffsetNewest)
+		if err != nil {
+			return ends, err
+		}
+		ends[p] = offset
+	}
+	return ends, nil
+}
+
+// checkNoRecentRecords returns an error when records were produced to the
+// topic within migrationProducerWindow, going by their timestamps, so that
+// producers that only write now and then aren't missed by watching the topic
+// for a while
+func (c *Client) checkNoRecentRecords(topic string, partitions []int32, ends map[int32]int64) error {
+	since := time.Now().Add(-migrationProducerWindow)
+	offsets := make(map[int32]int64, len(partitions))
+	for _, p := range partitions {
+		offset, err := c.client.GetOffset(topic, p, since.UnixNano()/int64(time.Millisecond))
+		if err != nil {
+			return err
+		}
+		offsets[p] = offset
+	}
+
+	if recent := partitionsWithRecords(offsets, ends); len(recent) > 0 {
+		return fmt.Errorf("topic %s has active producers, partitions %v have records produced in the last %s", topic, recent, migrationProducerWindow)
+	}
+	return nil
+}
+
+// partitionsWithRecords returns the partitions that have records from the
+// given offsets to their end. An offset of -1 means that there are none.
+func partitionsWithRecords(offsets, ends map[int32]int64) []int32 {
+	partitions := []int32{}
+	for p, offset := range offsets {
+		if offset >= 0 && offset < ends[p] {
+			partitions = append(partitions, p)
+		}
+	}
+	sort.Slice(partitions, func(i, j int) bool { return partitions[i] < partitions[j] })
+	return partitions
+}
+
+// checkNoNewRecords returns an error when records were produced to the topic
+// since its end offsets were taken
+func (c *Client) checkNoNewRecords(topic string, partitions []int32, ends map[int32]int64) error {
+	current, err := c.endOffsets(topic, partitions)
+	if err != nil {
+		return err
+	}
+
+	for _, p := range partitions {
+		if current[p] != ends[p] {
+			return fmt.Errorf("topic %s has active producers, partition %d grew from offset %d to %d", topic, p, ends[p], current[p])
+		}
+	}
+	return nil
+}
+
+// waitForTopic waits until the topic exists, or doesn't
+func (c *Client) waitForTopic(topic string, exists bool, deadline time.Time) error {
+	for {
+		if err := c.client.RefreshMetadata(); err != nil {
+			return err
+		}
+		if err := c.extractTopics(); err != nil {
+			return err
+		}
+		if _, ok := c.topics[topic]; ok == exists {
+			return nil
+		}
+
+		if time.Now().After(deadline) {
+			return fmt.Errorf("timed out waiting for topic %s (exists: %v)", topic, exists)
+		}
+		time.Sleep(time.Second)
+	}
+}
+
+// partitionCopy is how far a partition was copied
+type partitionCopy struct {
+	// the offsets of the partition that were to be copied, from Oldest to
+	// End excluded
+	Oldest int64
+	End    int64
+	// the offset of the last record copied, Oldest-1 when none was
+	Last   int64
+	Copied int64
+}
+
+// verifyCopy returns an error when a partition wasn't copied up to its end,
+// as the last record copied must be the last one of the partition. When
+// counts is given, the record count of each destination partition, taken from
+// its watermarks, must also be the number of records read from the source
+// partition, so that records lost or written twice are caught.
+func verifyCopy(copies map[int32]partitionCopy, counts map[int32]int64) error {
+	partitions := make([]int32, 0, len(copies))
+	for p := range copies {
+		partitions = append(partitions, p)
+	}
+	sort.Slice(partitions, func(i, j int) bool { return partitions[i] < partitions[j] })
+
+	for _, p := range partitions {
+		pc := copies[p]
+		if pc.Last < pc.End-1 {
+			return fmt.Errorf("partition %d was only copied up to offset %d of %d (%d records), it may end with transaction markers or the copy stopped early", p, pc.Last, pc.End-1, pc.Copied)
+		}
+		if count := counts[p]; counts != nil && count != pc.Copied {
+			return fmt.Errorf("partition %d of the destination has %d records, but %d were read from the source", p, count, pc.Copied)
+		}
+	}
+	return nil
+}
+
+func copiedRecords(copies map[int32]partitionCopy) int64 {
+	var copied int64
+	for _, pc := range copies {
+		copied += pc.Copied
+	}
+	return copied
+}
+
+func totalRecords(counts map[int32]int64) int64 {
+	var total int64
+	for _, n := range counts {
+		total += n
+	}
+	return total
+}
+
+// copyRecords copies the records of src up to the given end offsets to dst,
+// returning how far each partition was copied
+func (c *Client) copyRecords(src, dst string, ends map[int32]int64, partitionFor func(*sarama.ConsumerMessage) int32, deadline time.Time) (map[int32]partitionCopy, error) {
+	config := *c.kafkaConfig
+	config.Consumer.IsolationLevel = sarama.ReadCommitted
+	config.Consumer.Return.Errors = true
+	config.Producer.Partitioner = sarama.NewManualPartitioner
+	// an idempotent producer doesn't write records twice when it retries
+	config.Producer.Idempotent = true
+	config.Net.MaxOpenRequests = 1
+	config.Producer.RequiredAcks = sarama.WaitForAll
+	config.Producer.Return.Successes = true
+	config.Producer.MaxMessageBytes = int(sarama.MaxRequestSize)
+
+	consumer, err := sarama.NewConsumer(*c.config.BootstrapServers, &config)
+	if err != nil {
+		return nil, err
+	}
+	defer consumer.Close()
+
+	producer, err := sarama.NewSyncProducer(*c.config.BootstrapServers, &config)
+	if err != nil {
+		return nil, err
+	}
+	defer producer.Close()
+
+	partitions := make([]int32, 0, len(ends))
+	for p := range ends {
+		partitions = append(partitions, p)
+	}
+	sort.Slice(partitions, func(i, j int) bool { return partitions[i] < partitions[j] })
+
+	copies := make(map[int32]partitionCopy, len(partitions))
+	for _, p := range partitions {
+		oldest, err := c.client.GetOffset(src, p, sarama.OffsetOldest)
+		if err != nil {
+			return copies, err
+		}
+		pc := partitionCopy{Oldest: oldest, End: ends[p], Last: oldest - 1}
+		if oldest < ends[p] {
+			pc, err = copyPartition(consumer, producer, src, dst, p, pc, partitionFor, deadline)
+		}
+		copies[p] = pc
+		if err != nil {
+			return copies, fmt.Errorf("copying partition %d of %s: %w", p, src, err)
+		}
+	}
+
+	log.Printf("[DEBUG] Copied %d records from %s to %s", copiedRecords(copies), src, dst)
+	return copies, nil
+}
+
+// copyPartition copies the records of a partition from pc.Oldest up to
+// pc.End, stopping early when no record comes for migrationIdleTimeout
+func copyPartition(consumer sarama.Consumer, producer sarama.SyncProducer, src, dst string, p int32, pc partitionCopy, partitionFor func(*sarama.ConsumerMessage) int32, deadline time.Time) (partitionCopy, error) {
+	partitionConsumer, err := consumer.ConsumePartition(src, p, pc.Oldest)
+	if err != nil {
+		return pc, err
+	}
+	defer partitionConsumer.Close()
+
+	// the offset of the last record in batch
+	batchLast := pc.Last
+	batch := make([]*sarama.ProducerMessage, 0, migrationBatchSize)
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		if err := producer.SendMessages(batch); err != nil {
+			return err
+		}
+		pc.Copied += int64(len(batch))
+		pc.Last = batchLast
+		batch = batch[:0]
+		return nil
+	}
+
+consume:
+	for {
+		if time.Now().After(deadline) {
+			return pc, fmt.Errorf("timed out after copying %d records", pc.Copied)
+		}
+
+		select {
+		case msg, ok := <-partitionConsumer.Messages():
+			if !ok {
+				break consume
+			}
+			if msg.Offset >= pc.End {
+				break consume
+			}
+
+			batch = append(batch, copiedMessage(dst, partitionFor(msg), msg))
+			batchLast = msg.Offset
+			if len(batch) >= migrationBatchSize {
+				if err := flush(); err != nil {
+					return pc, err
+				}
+			}
+			if msg.Offset >= pc.End-1 {
+				break consume
+			}
+		case err := <-partitionConsumer.Errors():
+			return pc, err
+		case <-time.After(migrationIdleTimeout):
+			log.Printf("[WARN] No record of partition %d of %s after offset %d for %s", p, src, batchLast, migrationIdleTimeout)
+			break consume
+		}
+	}
+
+	return pc, flush()
+}
+
+func copiedMessage(topic string, partition int32, msg *sarama.ConsumerMessage) *sarama.ProducerMessage {
+	pm := &sarama.ProducerMessage{
+		Topic:     topic,
+		Partition: partition,
+		Timestamp: msg.Timestamp,
+	}
+	if msg.Key != nil {
+		pm.Key = sarama.ByteEncoder(msg.Key)
+	}
+	if msg.Value != nil {
+		pm.Value = sarama.ByteEncoder(msg.Value)
+	}
+	for _, h := range msg.Headers {
+		if h != nil {
+			pm.Headers = append(pm.Headers, *h)
+		}
+	}
+	return pm
+}
+
+// partitionForKey picks the partition the default partitioner of the Java
+// clients would use for the key. Records without a key are spread by the
+// partition they come from.
+func partitionForKey(key []byte, from, partitions int32) int32 {
+	if key == nil {
+		return from % partitions
+	}
+	return int32(murmur2(key)&0x7fffffff) % partitions
+}
+
+// murmur2 is the hash used by the default partitioner of the Java clients
+func murmur2(data []byte) uint32 {
+	const (
+		seed uint32 = 0x9747b28c
+		m    uint32 = 0x5bd1e995
+		r           = 24
+	)
+
+	length := len(data)
+	h := seed ^ uint32(length)
+
+	for i := 0; i+4 <= length; i += 4 {
+		k := uint32(data[i]) | uint32(data[i+1])<<8 | uint32(data[i+2])<<16 | uint32(data[i+3])<<24
+		k *= m
+		k ^= k >> r
+		k *= m
+		h *= m
+		h ^= k
+	}
+
+	tail := data[length&^3:]
+	switch len(tail) {
+	case 3:
+		h ^= uint32(tail[2]) << 16
+		fallthrough
+	case 2:
+		h ^= uint32(tail[1]) << 8
+		fallthrough
+	case 1:
+		h ^= uint32(tail[0])
+		h *= m
+	}
+
+	h ^= h >> 13
+	h *= m
+	h ^= h >> 15
+	return h
+}
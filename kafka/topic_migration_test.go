@@ -0,0 +1,183 @@
+package kafka
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/IBM/sarama"
+	"github.com/IBM/sarama/mocks"
+)
+
+func Test_Murmur2(t *testing.T) {
+	// the values the Java clients compute for these keys
+	cases := map[string]int32{
+		"21":                         -973932308,
+		"foobar":                     -790332482,
+		"a-little-bit-long-string":   -985981536,
+		"a-little-bit-longer-string": -1486304829,
+		"lkjh234lh9fiuh90y23oiuhsafujhadof229phr9h19h89h8": -58897971,
+		"abc": 479470107,
+	}
+
+	for key, expected := range cases {
+		if got := int32(murmur2([]byte(key))); got != expected {
+			t.Errorf("murmur2(%s): got %d, expected %d", key, got, expected)
+		}
+	}
+}
+
+func Test_PartitionForKey(t *testing.T) {
+	// toPositive(-790332482) % 3
+	if got := partitionForKey([]byte("foobar"), 5, 3); got != 1357151166%3 {
+		t.Errorf("Got partition %d for foobar, expected %d", got, 1357151166%3)
+	}
+
+	if got := partitionForKey(nil, 5, 3); got != 2 {
+		t.Errorf("Got partition %d for a record without key, expected 2", got)
+	}
+}
+
+func testCopyPartition(t *testing.T, records int, pc partitionCopy) (partitionCopy, error) {
+	config := sarama.NewConfig()
+	config.Producer.Return.Successes = true
+
+	consumer := mocks.NewConsumer(t, config)
+	partitionConsumer := consumer.ExpectConsumePartition("orders", 0, pc.Oldest)
+	// the mock gives the records the offsets 1, 2, ...
+	for i := 0; i < records; i++ {
+		partitionConsumer.YieldMessage(&sarama.ConsumerMessage{Key: []byte("k"), Value: []byte("v")})
+	}
+
+	producer := mocks.NewSyncProducer(t, config)
+	for i := 0; i < records; i++ {
+		producer.ExpectSendMessageAndSucceed()
+	}
+	defer producer.Close()
+	defer consumer.Close()
+
+	samePartition := func(msg *sarama.ConsumerMessage) int32 { return msg.Partition }
+	return copyPartition(consumer, producer, "orders", "orders-shrink-tmp", 0, pc, samePartition, time.Now().Add(time.Minute))
+}
+
+func Test_CopyPartition(t *testing.T) {
+	got, err := testCopyPartition(t, 3, partitionCopy{Oldest: 1, End: 4, Last: 0})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := partitionCopy{Oldest: 1, End: 4, Last: 3, Copied: 3}
+	if got != expected {
+		t.Errorf("Got %+v, expected %+v", got, expected)
+	}
+	if err := verifyCopy(map[int32]partitionCopy{0: got}, nil); err != nil {
+		t.Errorf("Expected the copy to be complete, got %s", err)
+	}
+}
+
+func Test_CopyPartitionStopsEarly(t *testing.T) {
+	idleTimeout := migrationIdleTimeout
+	migrationIdleTimeout = 10 * time.Millisecond
+	defer func() { migrationIdleTimeout = idleTimeout }()
+
+	// offsets 4 and 5 never come, as if they were transaction markers or
+	// the consumer stalled
+	got, err := testCopyPartition(t, 3, partitionCopy{Oldest: 1, End: 6, Last: 0})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := partitionCopy{Oldest: 1, End: 6, Last: 3, Copied: 3}
+	if got != expected {
+		t.Errorf("Got %+v, expected %+v", got, expected)
+	}
+	if err := verifyCopy(map[int32]partitionCopy{0: got}, nil); err == nil {
+		t.Errorf("Expected the copy up to offset 3 of 5 not to verify")
+	}
+}
+
+func Test_VerifyCopy(t *testing.T) {
+	for name, tc := range map[string]struct {
+		copies   map[int32]partitionCopy
+		counts   map[int32]int64
+		expected string
+	}{
+		"complete": {
+			copies: map[int32]partitionCopy{
+				0: {Oldest: 0, End: 10, Last: 9, Copied: 10},
+				1: {Oldest: 5, End: 5, Last: 4},
+			},
+		},
+		"stopped early": {
+			copies: map[int32]partitionCopy{
+				0: {Oldest: 0, End: 10, Last: 9, Copied: 10},
+				1: {Oldest: 0, End: 10, Last: 6, Copied: 7},
+			},
+			counts:   map[int32]int64{0: 10, 1: 7},
+			expected: "partition 1 was only copied up to offset 6 of 9 (7 records)",
+		},
+		"nothing copied": {
+			copies:   map[int32]partitionCopy{0: {Oldest: 3, End: 10, Last: 2}},
+			expected: "partition 0 was only copied up to offset 2 of 9 (0 records)",
+		},
+		"watermarks match": {
+			copies: map[int32]partitionCopy{0: {Oldest: 0, End: 10, Last: 9, Copied: 10}},
+			counts: map[int32]int64{0: 10},
+		},
+		// a retry wrote a record twice
+		"duplicates": {
+			copies:   map[int32]partitionCopy{0: {Oldest: 0, End: 10, Last: 9, Copied: 10}},
+			counts:   map[int32]int64{0: 11},
+			expected: "partition 0 of the destination has 11 records, but 10 were read from the source",
+		},
+		"missing in the destination": {
+			copies:   map[int32]partitionCopy{0: {Oldest: 0, End: 10, Last: 9, Copied: 10}},
+			counts:   map[int32]int64{},
+			expected: "partition 0 of the destination has 0 records, but 10 were read from the source",
+		},
+	} {
+		err := verifyCopy(tc.copies, tc.counts)
+		switch {
+		case tc.expected == "" && err != nil:
+			t.Errorf("%s: expected the copy to verify, got %s", name, err)
+		case tc.expected != "" && (err == nil || !strings.HasPrefix(err.Error(), tc.expected)):
+			t.Errorf("%s: got %v, expected %s", name, err, tc.expected)
+		}
+	}
+}
+
+func Test_PartitionsWithRecords(t *testing.T) {
+	offsets := map[int32]int64{0: -1, 1: 7, 2: 10, 3: 0}
+	ends := map[int32]int64{0: 10, 1: 10, 2: 10, 3: 0}
+
+	if got := partitionsWithRecords(offsets, ends); !reflect.DeepEqual(got, []int32{1}) {
+		t.Errorf("Got %v, expected [1]", got)
+	}
+}
+
+func Test_MigrationTopicConfig(t *testing.T) {
+	compact := "compact"
+	day := "86400000"
+	size := "2097152"
+	timestampType := "LogAppendTime"
+	throttled := "*"
+
+	got := migrationTopicConfig(map[string]*string{
+		"cleanup.policy":                          &compact,
+		"retention.ms":                            &day,
+		"max.message.bytes":                       &size,
+		"message.timestamp.type":                  &timestampType,
+		"leader.replication.throttled.replicas":   &throttled,
+		"follower.replication.throttled.replicas": &throttled,
+	})
+
+	expected := map[string]string{
+		"cleanup.policy":         "delete",
+		"retention.ms":           "-1",
+		"retention.bytes":        "-1",
+		"max.message.bytes":      "2097152",
+		"message.timestamp.type": "LogAppendTime",
+	}
+	if !reflect.DeepEqual(strPtrMapToStrMap(got), expected) {
+		t.Errorf("Got %v, expected %v", strPtrMapToStrMap(got), expected)
+	}
+}
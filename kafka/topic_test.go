@@ -0,0 +1,198 @@
+package kafka
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/IBM/sarama"
+)
+
+func Test_TopicNameCollision(t *testing.T) {
+	existing := []string{"orders.v1", "Payments", "logs"}
+
+	for _, name := range []string{"orders.v1", "orders.v2", "payments-v1", "logs_v1"} {
+		if err := topicNameCollision(name, existing); err != nil {
+			t.Errorf("expected '%s' not to collide, got %s", name, err)
+		}
+	}
+
+	for _, name := range []string{"orders_v1", "payments", "LOGS"} {
+		if err := topicNameCollision(name, existing); err == nil {
+			t.Errorf("expected '%s' to collide", name)
+		}
+	}
+
+	if !reflect.DeepEqual(existing, []string{"orders.v1", "Payments", "logs"}) {
+		t.Errorf("expected the existing topics to be left in order, got %v", existing)
+	}
+}
+
+func Test_NewTopicConfigEntry(t *testing.T) {
+	cases := []struct {
+		entry    sarama.ConfigEntry
+		version  int
+		expected string
+	}{
+		{sarama.ConfigEntry{Name: "retention.ms", Source: sarama.SourceTopic}, 1, "topic"},
+		{sarama.ConfigEntry{Name: "retention.ms", Source: sarama.SourceStaticBroker}, 1, "static_broker"},
+		{sarama.ConfigEntry{Name: "retention.ms", Source: sarama.SourceDynamicDefaultBroker}, 1, "dynamic_default_broker"},
+		{sarama.ConfigEntry{Name: "retention.ms", Source: sarama.SourceDefault, Default: true}, 0, "default"},
+		{sarama.ConfigEntry{Name: "retention.ms", Source: sarama.SourceUnknown}, 0, "topic"},
+		{sarama.ConfigEntry{Name: "retention.ms", Source: sarama.SourceUnknown}, 1, "unknown"},
+	}
+
+	for _, c := range cases {
+		if got := newTopicConfigEntry(&c.entry, c.version).Source; got != c.expected {
+			t.Errorf("%v (v%d): got source %s, expected %s", c.entry.Source, c.version, got, c.expected)
+		}
+	}
+}
+
+func Test_PinDeclaredConfig(t *testing.T) {
+	retention := "604800000"
+	segment := "1073741824"
+	config := map[string]*string{
+		"segment.bytes": &segment,
+	}
+	effective := []TopicConfigEntry{
+		{Name: "cleanup.policy", Value: "delete", Source: "default"},
+		{Name: "retention.ms", Value: retention, Source: "topic"},
+		{Name: "segment.bytes", Value: segment, Source: "dynamic_broker"},
+	}
+	declared := map[string]interface{}{
+		"cleanup.policy": "delete",
+		"retention.ms":   retention,
+	}
+
+	got := strPtrMapToStrMap(pinDeclaredConfig(config, effective, declared))
+	expected := map[string]string{
+		"retention.ms":  retention,
+		"segment.bytes": segment,
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Got %v, expected %v", got, expected)
+	}
+
+	delete(declared, "cleanup.policy")
+	declared["segment.bytes"] = segment
+	got = strPtrMapToStrMap(pinDeclaredConfig(config, effective, declared))
+	expected = map[string]string{
+		"retention.ms": retention,
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("a declared key inherited from the brokers should be left out, got %v", got)
+	}
+}
+
+func Test_MajorityReplicationFactor(t *testing.T) {
+	cases := []struct {
+		counts  map[int32]int16
+		rf      int16
+		uniform bool
+	}{
+		{map[int32]int16{0: 3, 1: 3, 2: 3}, 3, true},
+		{map[int32]int16{0: 3, 1: 2, 2: 3}, 3, false},
+		{map[int32]int16{0: 3, 1: 2, 2: 2, 3: 3}, 2, false},
+		{map[int32]int16{0: 1, 1: 4, 2: 2}, 1, false},
+	}
+
+	for _, c := range cases {
+		rf, uniform := majorityReplicationFactor(c.counts)
+		if rf != c.rf || uniform != c.uniform {
+			t.Errorf("%v: got (%d, %v), expected (%d, %v)", c.counts, rf, uniform, c.rf, c.uniform)
+		}
+	}
+}
+
+func Test_MatchTopics(t *testing.T) {
+	topics := []string{"payments-eu", "orders.v1", "payments-us", "orders.v2", "audit"}
+
+	cases := []struct {
+		id       string
+		expected []string
+	}{
+		{"prefix:orders.", []string{"orders.v1", "orders.v2"}},
+		{"regex:^payments-.*$", []string{"payments-eu", "payments-us"}},
+		{"regex:v[0-9]$", []string{"orders.v1", "orders.v2"}},
+	}
+
+	for _, c := range cases {
+		got, err := matchTopics(c.id, topics)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, c.expected) {
+			t.Errorf("%s: got %v, expected %v", c.id, got, c.expected)
+		}
+	}
+
+	for _, id := range []string{"prefix:billing", "regex:[", "orders.v1"} {
+		if _, err := matchTopics(id, topics); err == nil {
+			t.Errorf("expected an error importing '%s'", id)
+		}
+	}
+}
+
+func Test_ConfigChanges(t *testing.T) {
+	old := map[string]interface{}{
+		"retention.ms":   "1000",
+		"cleanup.policy": "compact",
+		"segment.bytes":  "1000000",
+	}
+	new := map[string]interface{}{
+		"retention.ms":        "2000",
+		"segment.bytes":       "1000000",
+		"min.insync.replicas": "2",
+	}
+
+	set, remove := configChanges(old, new)
+
+	expectedSet := map[string]string{
+		"retention.ms":        "2000",
+		"min.insync.replicas": "2",
+	}
+	if got := strPtrMapToStrMap(set); !reflect.DeepEqual(got, expectedSet) {
+		t.Errorf("Got %v to set, expected %v", got, expectedSet)
+	}
+	if !reflect.DeepEqual(remove, []string{"cleanup.policy"}) {
+		t.Errorf("Got %v to remove, expected [cleanup.policy]", remove)
+	}
+}
+
+func Test_ConfigAfterChanges(t *testing.T) {
+	effective := []TopicConfigEntry{
+		{Name: "cleanup.policy", Value: "compact", Source: "topic"},
+		{Name: "leader.replication.throttled.replicas", Value: "0:1", Source: "topic"},
+		{Name: "retention.ms", Value: "1000", Source: "topic"},
+		{Name: "segment.bytes", Value: "1000000", Source: "static_broker"},
+	}
+	retention := "2000"
+
+	got := strPtrMapToStrMap(configAfterChanges(effective, map[string]*string{"retention.ms": &retention}, []string{"cleanup.policy"}))
+	expected := map[string]string{
+		"leader.replication.throttled.replicas": "0:1",
+		"retention.ms":                          "2000",
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Got %v, expected %v", got, expected)
+	}
+}
+
+func Test_WithoutIgnoredConfig(t *testing.T) {
+	v := "x"
+	config := map[string]*string{
+		"leader.replication.throttled.replicas":   &v,
+		"follower.replication.throttled.replicas": &v,
+		"retention.bytes":                         &v,
+		"retention.ms":                            &v,
+	}
+
+	got := withoutIgnoredConfig(config, []string{"*.replication.throttled.replicas", "retention.bytes"})
+	if len(got) != 1 || got["retention.ms"] == nil {
+		t.Errorf("expected only retention.ms to be left, got %v", strPtrMapToStrMap(got))
+	}
+
+	if _, errs := validateConfigKeyPattern("[retention", "ignore_config_keys.0"); len(errs) == 0 {
+		t.Errorf("expected an invalid pattern to be rejected")
+	}
+}
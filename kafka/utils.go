@@ -1,11 +1,15 @@
 package kafka
 
 import (
+	"context"
 	"fmt"
+	"log"
 	"strings"
+	"time"
 )
 
-// MapEq compares two maps, and checks that the keys and values are the same
+// MapEq compares two maps, and checks that the keys and values are the same,
+// treating equivalent forms of a topic config value as equal
 func MapEq(result, expected map[string]*string) error {
 	if len(result) != len(expected) {
 		return fmt.Errorf("%v != %v", result, expected)
@@ -16,7 +20,7 @@ func MapEq(result, expected map[string]*string) error {
 			if resultV == nil && expectedV == nil {
 				continue
 			}
-			if *resultV != *expectedV {
+			if normalizeTopicConfig(expectedK, *resultV) != normalizeTopicConfig(expectedK, *expectedV) {
 				return fmt.Errorf("result[%s]: %s != expected[%s]: %s", expectedK, *resultV, expectedK, *expectedV)
 			}
 
@@ -48,3 +52,35 @@ func strPtrMapToStrMap(c map[string]*string) map[string]string {
 	}
 	return foo
 }
+
+// remainingTimeout returns the time left before ctx's deadline, or fallback if
+// ctx has no deadline.
+func remainingTimeout(ctx context.Context, fallback time.Duration) time.Duration {
+	if deadline, ok := ctx.Deadline(); ok {
+		return time.Until(deadline)
+	}
+	return fallback
+}
+
+// withContext runs f and returns its result, even when ctx is done first.
+// Requests to the brokers can't be interrupted, and giving up on one would
+// leave it running after Terraform was told it failed, so f is waited for;
+// the brokers get the remaining timeout, and sarama's network timeouts bound
+// how long an unresponsive broker can take.
+func withContext(ctx context.Context, f func() error) error {
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- f()
+	}()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+		log.Printf("[WARN] Waiting for a request to the brokers to finish past the timeout: %s", ctx.Err())
+		if err := <-errCh; err != nil {
+			return fmt.Errorf("%w (%s)", err, ctx.Err())
+		}
+		return nil
+	}
+}
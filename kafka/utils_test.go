@@ -1,8 +1,11 @@
 package kafka
 
 import (
+	"context"
+	"errors"
 	"reflect"
 	"testing"
+	"time"
 )
 
 func TestMapEq(t *testing.T) {
@@ -21,6 +24,18 @@ func TestMapEq(t *testing.T) {
 		t.Fatalf("%s", err)
 	}
 }
+func TestMapEqNormalizesTopicConfig(t *testing.T) {
+	a, b := "delete,compact", "compact,delete"
+	if err := MapEq(map[string]*string{"cleanup.policy": &a}, map[string]*string{"cleanup.policy": &b}); err != nil {
+		t.Fatalf("%s", err)
+	}
+
+	c, d := "1000", "2000"
+	if err := MapEq(map[string]*string{"retention.ms": &c}, map[string]*string{"retention.ms": &d}); err == nil {
+		t.Fatalf("expected %s and %s to differ", c, d)
+	}
+}
+
 func TestNonEmptyAndTrimmed(t *testing.T) {
 	input := []string{"Hello ", "", " World"}
 	expected := []string{"Hello", "World"}
@@ -30,3 +45,47 @@ func TestNonEmptyAndTrimmed(t *testing.T) {
 		t.Errorf("%v != %v", output, expected)
 	}
 }
+
+func TestWithContext(t *testing.T) {
+	expected := errors.New("failed")
+	err := withContext(context.Background(), func() error {
+		return expected
+	})
+	if err != expected {
+		t.Errorf("%v != %v", err, expected)
+	}
+
+	// a request that outlives the context is still waited for, so that
+	// its result isn't lost
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	done := false
+	err = withContext(ctx, func() error {
+		time.Sleep(50 * time.Millisecond)
+		done = true
+		return nil
+	})
+	if err != nil || !done {
+		t.Errorf("Expected the request to finish without an error, got %v", err)
+	}
+
+	err = withContext(ctx, func() error {
+		time.Sleep(50 * time.Millisecond)
+		return expected
+	})
+	if !errors.Is(err, expected) {
+		t.Errorf("%v doesn't wrap %v", err, expected)
+	}
+}
+
+func TestRemainingTimeout(t *testing.T) {
+	if got := remainingTimeout(context.Background(), time.Minute); got != time.Minute {
+		t.Errorf("%v != %v", got, time.Minute)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Hour)
+	defer cancel()
+	if got := remainingTimeout(ctx, time.Minute); got <= time.Minute || got > time.Hour {
+		t.Errorf("expected the time left before the deadline, got %v", got)
+	}
+}